@@ -66,6 +66,12 @@ func (w *AliLogHubWriter) Write(r *Record) (err error) {
 		Key:   proto.String("info"),
 		Value: proto.String(r.info),
 	})
+	for _, f := range r.fields {
+		content = append(content, &sls.LogContent{
+			Key:   proto.String(f.Key),
+			Value: proto.String(fieldString(f.Value)),
+		})
+	}
 	log := &sls.Log{
 		Time:     proto.Uint32(uint32(time.Now().Unix())),
 		Contents: content,
@@ -126,3 +132,8 @@ func (w *AliLogHubWriter) writeBuf(log *sls.Log) error {
 func (w *AliLogHubWriter) available() int {
 	return len(w.bufLogs) - w.n
 }
+
+// Level minimum level of the loghub writer
+func (w *AliLogHubWriter) Level() int {
+	return w.level
+}
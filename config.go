@@ -2,9 +2,14 @@ package log4go
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"log/syslog"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/kdpujie/log4go/util"
 )
@@ -13,12 +18,47 @@ type ConfFileWriter struct {
 	Level   string `json:"level"`
 	LogPath string `json:"log_path"`
 	On      bool   `json:"on"`
+	Format  string `json:"format"`   // "json", "logfmt" or a conversion pattern, default layout if empty
+	MaxSize int64  `json:"max_size"` // megabytes, roll to numbered backups beyond it, 0 disables
+
+	MaxBackups   int   `json:"max_backups"`    // rotated files to keep, 0 keeps all
+	MaxAge       int   `json:"max_age"`        // days to keep rotated files, 0 keeps all
+	MaxTotalSize int64 `json:"max_total_size"` // megabytes of all log files, 0 disables
+
+	Compression string `json:"compression"` // compress rotated files, "gzip" or a registered compressor
+	Symlink     string `json:"symlink"`     // link always pointing to the active file
+	Timezone    string `json:"timezone"`    // of the path variables, "UTC", "Local" or a name like "Asia/Shanghai"
+
+	BufferSize     int    `json:"buffer_size"`      // bytes buffered per file, default 8192
+	FlushPerRecord bool   `json:"flush_per_record"` // write every record to the OS immediately
+	SyncInterval   int64  `json:"sync_interval"`    // ms between fsync, at least 1000 when idle, 0 disables
+	SyncLevel      string `json:"sync_level"`       // fsync after every record at or above this level
+
+	FallbackPath string `json:"fallback_path"` // used while the log file is unwritable, stderr if empty
+
+	Shared     bool `json:"shared"`      // several processes append to the same file
+	LockWrites bool `json:"lock_writes"` // flock every write in shared mode
+
+	Header         bool   `json:"header"`          // write DefaultFileHeader on top of every new file
+	HeaderTemplate string `json:"header_template"` // text/template of the header, overrides the default
+
+	FileMode string `json:"file_mode"` // octal, eg: "0640", default "0644"
+	DirMode  string `json:"dir_mode"`  // octal, eg: "0750", default "0755"
+	UID      *int   `json:"uid"`       // chown the files to uid and gid when both are set
+	GID      *int   `json:"gid"`
 }
 
 type ConfConsoleWriter struct {
-	Level string `json:"level"`
-	On    bool   `json:"on"`
-	Color bool   `json:"color"`
+	Level     string     `json:"level"`
+	On        bool       `json:"on"`
+	Color     bool       `json:"color"`      // color always, kept for compatibility, see ColorMode
+	ColorMode *ColorMode `json:"color_mode"` // "never", "always" or "auto", overrides Color
+	Format    string     `json:"format"`     // "json", "logfmt" or a conversion pattern, default layout if empty
+	Theme     *Theme     `json:"theme"`      // name of a registered theme or a theme object
+	Dev       bool       `json:"dev"`        // development mode, also on with LOG4GO_ENV=dev
+
+	Output     string `json:"output"`      // "stdout" (default) or "stderr"
+	SplitLevel string `json:"split_level"` // records at or above it go to stderr, eg: "WARN"
 }
 
 type ConfAliLogHubWriter struct {
@@ -34,6 +74,16 @@ type ConfAliLogHubWriter struct {
 	BufSize         int    `json:"buf_size"`
 }
 
+type ConfSyslogWriter struct {
+	Level    string `json:"level"`
+	On       bool   `json:"on"`
+	Network  string `json:"network"`  // "udp", "tcp" or "unixgram", the local syslog daemon if empty
+	Addr     string `json:"addr"`     // eg: "127.0.0.1:514"
+	Tag      string `json:"tag"`      // program name if empty
+	Facility string `json:"facility"` // eg: "user" or "local0", default "syslog"
+	Format   string `json:"format"`   // "json", "logfmt" or a conversion pattern, "<file:line> message" if empty
+}
+
 // LogConfig log config
 type LogConfig struct {
 	Level           string              `json:"level"`
@@ -42,6 +92,7 @@ type LogConfig struct {
 	ConsoleWriter   ConfConsoleWriter   `json:"console_writer"`
 	AliLoghubWriter ConfAliLogHubWriter `json:"ali_loghub_writer"`
 	KafKaWriter     ConfKafKaWriter     `json:"kafka_writer"`
+	SyslogWriter    ConfSyslogWriter    `json:"syslog_writer"`
 }
 
 // SetupLog setup log
@@ -54,14 +105,80 @@ func SetupLog(lc LogConfig) (err error) {
 	if lc.FileWriter.On {
 		w := NewFileWriter()
 		w.level = getLevel0(lc.FileWriter.Level, defaultLevel)
-		w.SetPathPattern(lc.FileWriter.LogPath)
+		if err = w.SetPathPattern(lc.FileWriter.LogPath); err != nil {
+			return
+		}
+		w.SetMaxSize(lc.FileWriter.MaxSize * 1024 * 1024)
+		w.SetMaxBackups(lc.FileWriter.MaxBackups)
+		w.SetMaxAge(time.Duration(lc.FileWriter.MaxAge) * 24 * time.Hour)
+		w.SetMaxTotalSize(lc.FileWriter.MaxTotalSize * 1024 * 1024)
+		if err = w.SetCompression(lc.FileWriter.Compression); err != nil {
+			return
+		}
+		w.SetSymlink(lc.FileWriter.Symlink)
+		if lc.FileWriter.Timezone != "" {
+			var loc *time.Location
+			if loc, err = time.LoadLocation(lc.FileWriter.Timezone); err != nil {
+				return
+			}
+			w.SetLocation(loc)
+		}
+		w.SetBufferSize(lc.FileWriter.BufferSize)
+		w.SetFlushPerRecord(lc.FileWriter.FlushPerRecord)
+		w.SetSyncInterval(time.Duration(lc.FileWriter.SyncInterval) * time.Millisecond)
+		if lc.FileWriter.SyncLevel != "" {
+			w.SetSyncLevel(getLevel(lc.FileWriter.SyncLevel))
+		}
+		if err = setFileWriterPerm(w, &lc.FileWriter); err != nil {
+			return
+		}
+		w.SetHeader(lc.FileWriter.Header)
+		if lc.FileWriter.HeaderTemplate != "" {
+			if err = w.SetHeaderTemplate(lc.FileWriter.HeaderTemplate); err != nil {
+				return
+			}
+		}
+		w.SetShared(lc.FileWriter.Shared)
+		w.SetLockWrites(lc.FileWriter.LockWrites)
+		if lc.FileWriter.FallbackPath != "" {
+			if err = w.SetFallbackPath(lc.FileWriter.FallbackPath); err != nil {
+				return
+			}
+		}
+		if w.formatter, err = NewFormatter(lc.FileWriter.Format); err != nil {
+			return
+		}
 		Register(w)
 	}
 
 	if lc.ConsoleWriter.On {
 		w := NewConsoleWriter()
 		w.level = getLevel0(lc.ConsoleWriter.Level, defaultLevel)
-		w.SetColor(lc.ConsoleWriter.Color)
+		// unset colors keep the writer default, eg: the LOG4GO_ENV=dev preset
+		if lc.ConsoleWriter.ColorMode != nil {
+			w.SetColorMode(*lc.ConsoleWriter.ColorMode)
+		} else if lc.ConsoleWriter.Color {
+			w.SetColorMode(ColorAlways)
+		}
+		if err = w.SetTheme(lc.ConsoleWriter.Theme); err != nil {
+			return
+		}
+		if lc.ConsoleWriter.Dev {
+			w.SetDevelopment(true)
+		}
+		switch strings.ToLower(lc.ConsoleWriter.Output) {
+		case "", "stdout":
+		case "stderr":
+			w.SetOutput(os.Stderr)
+		default:
+			return errors.New("Invalid console output (" + lc.ConsoleWriter.Output + ")")
+		}
+		if lc.ConsoleWriter.SplitLevel != "" {
+			w.SetSplit(getLevel(lc.ConsoleWriter.SplitLevel))
+		}
+		if w.formatter, err = NewFormatter(lc.ConsoleWriter.Format); err != nil {
+			return
+		}
 		Register(w)
 	}
 
@@ -83,10 +200,49 @@ func SetupLog(lc LogConfig) (err error) {
 		w.level = getLevel0(lc.KafKaWriter.Level, defaultLevel)
 		Register(w)
 	}
+
+	if lc.SyslogWriter.On {
+		w := NewSyslogWriterWithLevel(getLevel0(lc.SyslogWriter.Level, defaultLevel))
+		w.SetNetwork(lc.SyslogWriter.Network)
+		w.SetAddr(lc.SyslogWriter.Addr)
+		w.SetTag(lc.SyslogWriter.Tag)
+		if lc.SyslogWriter.Facility != "" {
+			var facility syslog.Priority
+			if facility, err = parseFacility(lc.SyslogWriter.Facility); err != nil {
+				return
+			}
+			w.SetFacility(facility)
+		}
+		if w.formatter, err = NewFormatter(lc.SyslogWriter.Format); err != nil {
+			return
+		}
+		Register(w)
+	}
 	// 全局配置
 	return nil
 }
 
+func setFileWriterPerm(w *FileWriter, conf *ConfFileWriter) error {
+	if conf.FileMode != "" {
+		mode, err := strconv.ParseUint(conf.FileMode, 8, 32)
+		if err != nil {
+			return err
+		}
+		w.SetFileMode(os.FileMode(mode))
+	}
+	if conf.DirMode != "" {
+		mode, err := strconv.ParseUint(conf.DirMode, 8, 32)
+		if err != nil {
+			return err
+		}
+		w.SetDirMode(os.FileMode(mode))
+	}
+	if conf.UID != nil && conf.GID != nil {
+		w.SetOwner(*conf.UID, *conf.GID)
+	}
+	return nil
+}
+
 // SetupLogWithConf setup log with config file
 func SetupLogWithConf(file string) (err error) {
 	var lc LogConfig
@@ -0,0 +1,76 @@
+package log4go
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// ColorMode whether the console writer colors its output
+type ColorMode int
+
+const (
+	ColorNever ColorMode = iota
+	ColorAlways
+	ColorAuto // color terminals only, see autoColor
+)
+
+// UnmarshalJSON accept true, false, "always", "never" and "auto"
+func (m *ColorMode) UnmarshalJSON(b []byte) error {
+	var on bool
+	if err := json.Unmarshal(b, &on); err == nil {
+		*m = ColorNever
+		if on {
+			*m = ColorAlways
+		}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	switch strings.ToLower(s) {
+	case "", "never", "false":
+		*m = ColorNever
+	case "always", "true":
+		*m = ColorAlways
+	case "auto":
+		*m = ColorAuto
+	default:
+		return errors.New("Invalid color mode (" + s + ")")
+	}
+	return nil
+}
+
+// autoColor color decision for out following the usual conventions:
+// FORCE_COLOR forces color unless it is 0 or false, NO_COLOR and TERM=dumb
+// disable it, otherwise only terminals are colored
+func autoColor(out io.Writer) bool {
+	if force, ok := os.LookupEnv("FORCE_COLOR"); ok {
+		return force != "0" && !strings.EqualFold(force, "false")
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(out)
+}
+
+// isTerminal report whether out is a character device such as a terminal,
+// pipes and regular files are not
+func isTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,142 @@
+package log4go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// EnvKey environment variable selecting presets, LOG4GO_ENV=dev turns on
+// the development mode and automatic colors of new console writers
+const EnvKey = "LOG4GO_ENV"
+
+const (
+	devTimeWidth   = 9  // +123.456s, longer times push the line right
+	devCallerWidth = 24 // trailing part of the caller path
+	devIndent      = "    "
+)
+
+// plainThemeStyles development mode output without colors
+var plainThemeStyles = &themeStyles{}
+
+func isDevEnv() bool {
+	return strings.EqualFold(os.Getenv(EnvKey), "dev")
+}
+
+// SetDevelopment render human friendly lines for local development, eg:
+//
+//	+1.203s INFO  handler/user.go:42       user login
+//	    user_id: 42
+//	    err: read tcp: i/o timeout
+//
+// times are relative to this call, fields and multi-line values such as
+// stack traces are printed below the message
+func (w *ConsoleWriter) SetDevelopment(on bool) {
+	w.dev = on
+	if on {
+		w.devStart = time.Now()
+	}
+}
+
+// formatDev render r in development mode relative to start
+func (r *colorRecord) formatDev(s *themeStyles, start time.Time) string {
+	var buf bytes.Buffer
+
+	elapsed := fmt.Sprintf("+%.3fs", r.now.Sub(start).Seconds())
+	paint(&buf, s.time, padLeft(elapsed, devTimeWidth))
+	buf.WriteByte(' ')
+	paint(&buf, s.levels[r.level], padRight(LEVEL_FLAGS[r.level], 5))
+	buf.WriteByte(' ')
+	// padded outside the style so an underline covers the caller only
+	caller := devCaller(r.file, r.line, r.code)
+	paint(&buf, s.caller, caller)
+	buf.WriteString(padRight("", devCallerWidth-utf8.RuneCountInString(caller)+1))
+	paint(&buf, s.message, r.info)
+	buf.WriteByte('\n')
+
+	for _, f := range r.fields {
+		buf.WriteString(devIndent)
+		paint(&buf, s.fieldKey, f.Key)
+		buf.WriteByte(':')
+
+		value, seq := devFieldValue(f.Value), s.fieldValue
+		if _, ok := f.Value.(error); ok {
+			seq = s.err
+		}
+		lines := strings.Split(strings.TrimRight(value, "\n"), "\n")
+		if len(lines) == 1 {
+			buf.WriteByte(' ')
+			paint(&buf, seq, lines[0])
+			buf.WriteByte('\n')
+			continue
+		}
+		buf.WriteByte('\n')
+		for _, line := range lines {
+			buf.WriteString(devIndent + devIndent)
+			paint(&buf, seq, line)
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.String()
+}
+
+// devCaller last two elements of the caller path, cut on the left to
+// devCallerWidth, eg: handler/user.go:42
+func devCaller(file string, line int, code string) string {
+	if file == "" {
+		return code
+	}
+	short := file
+	if i := strings.LastIndexByte(file, '/'); i > 0 {
+		if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+			short = file[j+1:]
+		}
+	}
+	short += ":" + strconv.Itoa(line)
+
+	if n := utf8.RuneCountInString(short); n > devCallerWidth {
+		runes := []rune(short)
+		short = "…" + string(runes[n-devCallerWidth+1:])
+	}
+	return short
+}
+
+// devFieldValue value of a field for development mode, errors keep the
+// details of %+v such as stack traces, composite values are indented json
+func devFieldValue(v interface{}) string {
+	switch val := v.(type) {
+	case nil, string, fmt.Stringer:
+		return fieldString(v)
+	case error:
+		return fmt.Sprintf("%+v", val)
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		if b, err := json.MarshalIndent(v, "", "  "); err == nil {
+			return string(b)
+		}
+		return fmt.Sprintf("%+v", v)
+	}
+	return fieldString(v)
+}
+
+func padLeft(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return strings.Repeat(" ", width-n) + s
+	}
+	return s
+}
+
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
@@ -0,0 +1,319 @@
+package log4go
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Style ANSI style of one element of a colored console line. A color is
+// a name such as "red" or "bright-blue", a 256-color palette index such as
+// "208", a truecolor "#ff8700", or "default" for the terminal default.
+type Style struct {
+	Color      string `json:"color"`
+	Background string `json:"background"`
+	Bold       bool   `json:"bold"`
+	Underline  bool   `json:"underline"`
+}
+
+// Theme styles of the colored console output. Unset styles are inherited
+// from the Base theme, so a theme only lists what it changes, eg:
+//
+//	{"base": "light", "levels": {"DEBUG": {"color": "#808080"}}}
+type Theme struct {
+	Base       string           `json:"base"`   // registered theme, "default" if empty
+	Levels     map[string]Style `json:"levels"` // by level name, eg: "WARN"
+	Time       Style            `json:"time"`
+	Caller     Style            `json:"caller"`
+	Message    Style            `json:"message"`
+	FieldKey   Style            `json:"field_key"`
+	FieldValue Style            `json:"field_value"`
+	Error      Style            `json:"error"` // field values that are errors
+}
+
+// UnmarshalJSON accept a theme object or the name of a registered theme
+func (t *Theme) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		*t = Theme{Base: name}
+		return nil
+	}
+	type theme Theme // without this method
+	return json.Unmarshal(b, (*theme)(t))
+}
+
+var (
+	themesMu sync.RWMutex
+	themes   = map[string]*Theme{
+		// readable on dark and light terminals
+		"default": {
+			Levels: map[string]Style{
+				"DEBUG": {Color: "blue"},
+				"INFO":  {Color: "green"},
+				"WARN":  {Color: "yellow"},
+				"ERROR": {Color: "red"},
+				"FATAL": {Color: "magenta", Bold: true},
+			},
+			Time:     Style{Color: "cyan"},
+			Caller:   Style{Underline: true},
+			FieldKey: Style{Color: "cyan"},
+			Error:    Style{Color: "red", Bold: true},
+		},
+		// darker 256-color palette for light backgrounds
+		"light": {
+			Levels: map[string]Style{
+				"DEBUG": {Color: "25"},
+				"INFO":  {Color: "28"},
+				"WARN":  {Color: "130"},
+				"ERROR": {Color: "160"},
+				"FATAL": {Color: "90", Bold: true},
+			},
+			Time:     Style{Color: "24"},
+			Caller:   Style{Color: "240", Underline: true},
+			FieldKey: Style{Color: "24"},
+			Error:    Style{Color: "160", Bold: true},
+		},
+		// the colors of the earlier releases
+		"classic": {
+			Levels: map[string]Style{
+				"DEBUG": {Color: "blue"},
+				"INFO":  {Color: "green"},
+				"WARN":  {Color: "yellow"},
+				"ERROR": {Color: "red"},
+				"FATAL": {Color: "magenta"},
+			},
+			Time:   Style{Color: "cyan"},
+			Caller: Style{Color: "black", Background: "white"},
+		},
+		// no colors, only emphasis
+		"mono": {
+			Levels: map[string]Style{
+				"WARN":  {Bold: true},
+				"ERROR": {Bold: true},
+				"FATAL": {Bold: true, Underline: true},
+			},
+			Caller: Style{Underline: true},
+			Error:  Style{Bold: true},
+		},
+	}
+
+	defaultThemeStyles = mustThemeStyles(&Theme{})
+)
+
+// RegisterTheme make a theme available by name, to SetTheme and as the
+// Base of other themes. "default", "light", "classic" and "mono" are built in.
+func RegisterTheme(name string, t *Theme) error {
+	resolved, err := t.resolve()
+	if err != nil {
+		return err
+	}
+	if _, err = resolved.styles(); err != nil {
+		return err
+	}
+
+	themesMu.Lock()
+	defer themesMu.Unlock()
+	themes[name] = resolved
+	return nil
+}
+
+// SetTheme color the console output with t, nil restores the default theme
+func (w *ConsoleWriter) SetTheme(t *Theme) error {
+	if t == nil {
+		w.styles = nil
+		return nil
+	}
+	resolved, err := t.resolve()
+	if err != nil {
+		return err
+	}
+	styles, err := resolved.styles()
+	if err != nil {
+		return err
+	}
+	w.styles = styles
+	return nil
+}
+
+// resolve copy of t with the unset styles taken from its base theme
+func (t *Theme) resolve() (*Theme, error) {
+	name := t.Base
+	if name == "" {
+		name = "default"
+	}
+	themesMu.RLock()
+	base, ok := themes[name]
+	themesMu.RUnlock()
+	if !ok {
+		return nil, errors.New("Unknown theme (" + name + ")")
+	}
+
+	resolved := &Theme{
+		Levels:     make(map[string]Style, len(LEVEL_FLAGS)),
+		Time:       inheritStyle(t.Time, base.Time),
+		Caller:     inheritStyle(t.Caller, base.Caller),
+		Message:    inheritStyle(t.Message, base.Message),
+		FieldKey:   inheritStyle(t.FieldKey, base.FieldKey),
+		FieldValue: inheritStyle(t.FieldValue, base.FieldValue),
+		Error:      inheritStyle(t.Error, base.Error),
+	}
+	for level, s := range base.Levels {
+		resolved.Levels[level] = s
+	}
+	for level, s := range t.Levels {
+		flag := strings.ToUpper(strings.TrimSpace(level))
+		if !isLevelFlag(flag) {
+			return nil, errors.New("Unknown level (" + level + ") in theme")
+		}
+		resolved.Levels[flag] = s
+	}
+	return resolved, nil
+}
+
+func inheritStyle(s, base Style) Style {
+	if s == (Style{}) {
+		return base
+	}
+	return s
+}
+
+func isLevelFlag(flag string) bool {
+	for _, f := range LEVEL_FLAGS {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// themeStyles escape sequences of a resolved theme, "" leaves the element plain
+type themeStyles struct {
+	levels     [len(LEVEL_FLAGS)]string
+	time       string
+	caller     string
+	message    string
+	fieldKey   string
+	fieldValue string
+	err        string
+}
+
+func (t *Theme) styles() (*themeStyles, error) {
+	s := &themeStyles{}
+	for i, flag := range LEVEL_FLAGS {
+		seq, err := t.Levels[flag].sequence()
+		if err != nil {
+			return nil, err
+		}
+		s.levels[i] = seq
+	}
+
+	elements := []struct {
+		style Style
+		seq   *string
+	}{
+		{t.Time, &s.time},
+		{t.Caller, &s.caller},
+		{t.Message, &s.message},
+		{t.FieldKey, &s.fieldKey},
+		{t.FieldValue, &s.fieldValue},
+		{t.Error, &s.err},
+	}
+	for _, e := range elements {
+		seq, err := e.style.sequence()
+		if err != nil {
+			return nil, err
+		}
+		*e.seq = seq
+	}
+	return s, nil
+}
+
+func mustThemeStyles(t *Theme) *themeStyles {
+	resolved, err := t.resolve()
+	if err != nil {
+		panic(err)
+	}
+	s, err := resolved.styles()
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// sequence SGR escape sequence of s, eg: "\033[1;38;5;208m"
+func (s Style) sequence() (string, error) {
+	var params []string
+	if s.Bold {
+		params = append(params, "1")
+	}
+	if s.Underline {
+		params = append(params, "4")
+	}
+	fg, err := colorParams(s.Color, 30)
+	if err != nil {
+		return "", err
+	}
+	bg, err := colorParams(s.Background, 40)
+	if err != nil {
+		return "", err
+	}
+	params = append(params, fg...)
+	params = append(params, bg...)
+
+	if len(params) == 0 {
+		return "", nil
+	}
+	return "\033[" + strings.Join(params, ";") + "m", nil
+}
+
+var colorNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// colorParams SGR parameters of color, base is 30 for foreground and 40
+// for background
+func colorParams(color string, base int) ([]string, error) {
+	c := strings.ToLower(strings.TrimSpace(color))
+	switch {
+	case c == "":
+		return nil, nil
+	case c == "default":
+		return []string{strconv.Itoa(base + 9)}, nil
+	case c == "gray" || c == "grey":
+		c = "bright-black"
+	}
+
+	for i, name := range colorNames {
+		if c == name {
+			return []string{strconv.Itoa(base + i)}, nil
+		}
+		if c == "bright-"+name {
+			return []string{strconv.Itoa(base + 60 + i)}, nil
+		}
+	}
+
+	if strings.HasPrefix(c, "#") && len(c) == 7 {
+		rgb, err := strconv.ParseUint(c[1:], 16, 32)
+		if err == nil {
+			return []string{strconv.Itoa(base + 8), "2",
+				strconv.Itoa(int(rgb >> 16)), strconv.Itoa(int(rgb >> 8 & 0xff)), strconv.Itoa(int(rgb & 0xff))}, nil
+		}
+	}
+
+	if n, err := strconv.Atoi(c); err == nil && n >= 0 && n <= 255 {
+		return []string{strconv.Itoa(base + 8), "5", strconv.Itoa(n)}, nil
+	}
+	return nil, errors.New("Invalid color (" + color + ")")
+}
+
+// paint write s wrapped in the escape sequence seq and a reset
+func paint(buf *bytes.Buffer, seq, s string) {
+	if seq == "" {
+		buf.WriteString(s)
+		return
+	}
+	buf.WriteString(seq)
+	buf.WriteString(s)
+	buf.WriteString("\033[0m")
+}
@@ -1,47 +1,67 @@
 package log4go
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"time"
 )
 
 type colorRecord Record
 
+// String render r with the default theme
 func (r *colorRecord) String() string {
-	switch r.level {
-	case DEBUG:
-		return fmt.Sprintf("\033[36m%s\033[0m [\033[34m%s\033[0m] \033[47;30m%s\033[0m %s\n",
-			r.time, LEVEL_FLAGS[r.level], r.code, r.info)
-
-	case INFO:
-		return fmt.Sprintf("\033[36m%s\033[0m [\033[32m%s\033[0m] \033[47;30m%s\033[0m %s\n",
-			r.time, LEVEL_FLAGS[r.level], r.code, r.info)
-
-	case WARNING:
-		return fmt.Sprintf("\033[36m%s\033[0m [\033[33m%s\033[0m] \033[47;30m%s\033[0m %s\n",
-			r.time, LEVEL_FLAGS[r.level], r.code, r.info)
-
-	case ERROR:
-		return fmt.Sprintf("\033[36m%s\033[0m [\033[31m%s\033[0m] \033[47;30m%s\033[0m %s\n",
-			r.time, LEVEL_FLAGS[r.level], r.code, r.info)
-
-	case FATAL:
-		return fmt.Sprintf("\033[36m%s\033[0m [\033[35m%s\033[0m] \033[47;30m%s\033[0m %s\n",
-			r.time, LEVEL_FLAGS[r.level], r.code, r.info)
-	}
+	return r.format(defaultThemeStyles)
+}
 
-	return ""
+// format render r with the escape sequences of a theme, error field values
+// are highlighted apart from the other values
+func (r *colorRecord) format(s *themeStyles) string {
+	var buf bytes.Buffer
+	paint(&buf, s.time, r.time)
+	buf.WriteString(" [")
+	paint(&buf, s.levels[r.level], LEVEL_FLAGS[r.level])
+	buf.WriteString("] ")
+	paint(&buf, s.caller, r.code)
+	buf.WriteByte(' ')
+	paint(&buf, s.message, r.info)
+	for _, f := range r.fields {
+		buf.WriteByte(' ')
+		paint(&buf, s.fieldKey, f.Key)
+		buf.WriteByte('=')
+		value := quoteFieldValue(fieldString(f.Value))
+		if _, ok := f.Value.(error); ok {
+			paint(&buf, s.err, value)
+		} else {
+			paint(&buf, s.fieldValue, value)
+		}
+	}
+	buf.WriteByte('\n')
+	return buf.String()
 }
 
 // ConsoleWriter console writer define
 type ConsoleWriter struct {
-	level int
-	color bool
+	level     int
+	color     ColorMode
+	formatter Formatter
+	styles    *themeStyles // default theme if nil
+	dev       bool         // development mode, see SetDevelopment
+	devStart  time.Time
+
+	out        io.Writer // os.Stdout if nil
+	errOut     io.Writer // os.Stderr if nil, used in split mode
+	outColor   bool      // color decision of out in auto mode
+	errColor   bool      // color decision of errOut in auto mode
+	split      bool      // records at or above splitLevel go to errOut
+	splitLevel int
 }
 
-// NewConsoleWriter create new console writer
+// NewConsoleWriter create new console writer, LOG4GO_ENV=dev turns on the
+// development mode with automatic colors
 func NewConsoleWriter() *ConsoleWriter {
-	return &ConsoleWriter{}
+	return NewConsoleWriterWithLevel(DEBUG)
 }
 
 // NewConsoleWriterWithLevel create new console writer with level
@@ -55,9 +75,16 @@ func NewConsoleWriterWithLevel(level int) *ConsoleWriter {
 	if level >= defaultLevel && level <= maxLevel {
 		defaultLevel = level
 	}
-	return &ConsoleWriter{
-		level: defaultLevel,
+	w := &ConsoleWriter{
+		level:    defaultLevel,
+		outColor: autoColor(os.Stdout),
+		errColor: autoColor(os.Stderr),
+	}
+	if isDevEnv() {
+		w.SetDevelopment(true)
+		w.color = ColorAuto
 	}
+	return w
 }
 
 // Write console write
@@ -65,14 +92,74 @@ func (w *ConsoleWriter) Write(r *Record) error {
 	if r.level < w.level {
 		return nil
 	}
-	if w.color {
-		fmt.Fprint(os.Stdout, ((*colorRecord)(r)).String())
+	out := w.output(r.level)
+	if w.formatter != nil {
+		fmt.Fprint(out, w.formatter.Format(r))
+	} else if w.dev {
+		styles := plainThemeStyles
+		if w.useColor(r.level) {
+			styles = w.themeStyles()
+		}
+		fmt.Fprint(out, ((*colorRecord)(r)).formatDev(styles, w.devStart))
+	} else if w.useColor(r.level) {
+		fmt.Fprint(out, ((*colorRecord)(r)).format(w.themeStyles()))
 	} else {
-		fmt.Fprint(os.Stdout, r.String())
+		fmt.Fprint(out, r.String())
 	}
 	return nil
 }
 
+func (w *ConsoleWriter) themeStyles() *themeStyles {
+	if w.styles == nil {
+		return defaultThemeStyles
+	}
+	return w.styles
+}
+
+// output target of a record at level
+func (w *ConsoleWriter) output(level int) io.Writer {
+	if w.splitOff(level) {
+		if w.errOut != nil {
+			return w.errOut
+		}
+		return os.Stderr
+	}
+	if w.out != nil {
+		return w.out
+	}
+	return os.Stdout
+}
+
+// SetOutput write to out instead of os.Stdout, eg: os.Stderr
+func (w *ConsoleWriter) SetOutput(out io.Writer) {
+	w.out = out
+	if out == nil {
+		out = os.Stdout
+	}
+	w.outColor = autoColor(out)
+}
+
+// splitOff report whether records at level go to the error output
+func (w *ConsoleWriter) splitOff(level int) bool {
+	return w.split && level >= w.splitLevel
+}
+
+// SetSplit write records at or above level to os.Stderr, or the writer of
+// SetErrorOutput, and the others to the output, eg: WARNING
+func (w *ConsoleWriter) SetSplit(level int) {
+	w.split = true
+	w.splitLevel = level
+}
+
+// SetErrorOutput target of the records split off by SetSplit, os.Stderr by default
+func (w *ConsoleWriter) SetErrorOutput(out io.Writer) {
+	w.errOut = out
+	if out == nil {
+		out = os.Stderr
+	}
+	w.errColor = autoColor(out)
+}
+
 // Init console init without implement
 func (w *ConsoleWriter) Init() error {
 	return nil
@@ -80,5 +167,40 @@ func (w *ConsoleWriter) Init() error {
 
 // SetColor console output color control
 func (w *ConsoleWriter) SetColor(c bool) {
-	w.color = c
+	if c {
+		w.color = ColorAlways
+	} else {
+		w.color = ColorNever
+	}
+}
+
+// SetColorMode console output color control, ColorAuto colors only
+// terminals and honors NO_COLOR, FORCE_COLOR and TERM=dumb
+func (w *ConsoleWriter) SetColorMode(mode ColorMode) {
+	w.color = mode
+}
+
+// useColor report whether records at level are colored
+func (w *ConsoleWriter) useColor(level int) bool {
+	switch w.color {
+	case ColorAlways:
+		return true
+	case ColorAuto:
+		if w.splitOff(level) {
+			return w.errColor
+		}
+		return w.outColor
+	}
+	return false
+}
+
+// SetFormatter set the formatter of the console writer, it takes
+// precedence over color, nil restores the default layout
+func (w *ConsoleWriter) SetFormatter(f Formatter) {
+	w.formatter = f
+}
+
+// Level minimum level of the console writer
+func (w *ConsoleWriter) Level() int {
+	return w.level
 }
@@ -14,8 +14,14 @@ func SetLog() {
 	   %D  day     (eg: 05)
 	   %H  hour    (eg: 18)
 	   %m  minute  (eg: 29)
-
-	   notice: No second's variable
+	   %S  second  (eg: 08)
+	   %W  ISO week number (eg: 42), use it with %G, eg: app-%G-W%W.log
+	   %G  ISO week year   (eg: 2025 on 2024-12-30)
+	   %j  day of year     (eg: 291)
+	   %L  level   (eg: error), one file per level
+
+	   resolved once when the pattern is set:
+	   %{hostname}  %{pid}  %{program}  %{env:POD_NAME}
 	*/
 	w.SetPathPattern("/tmp/logs/error%Y%M%D%H%m.log")
 
@@ -24,4 +24,8 @@ func main() {
 	logger.Warn("log4go by %s", name)
 	logger.Error("log4go by %s", name)
 	logger.Fatal("log4go by %s", name)
+
+	// child logger carrying structured fields
+	reqLogger := logger.With("request_id", "a1b2c3", "user_id", 42)
+	reqLogger.Error("request failed by %s", name)
 }
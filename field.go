@@ -0,0 +1,111 @@
+package log4go
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Field structured key/value pair carried by a record
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// String render field as key=value
+func (f Field) String() string {
+	return f.Key + "=" + quoteFieldValue(fieldString(f.Value))
+}
+
+// makeFields convert alternating key/value arguments to fields,
+// a dangling key gets a nil value
+func makeFields(kvs []interface{}) []Field {
+	fields := make([]Field, 0, (len(kvs)+1)/2)
+	for i := 0; i < len(kvs); i += 2 {
+		var key string
+		switch k := kvs[i].(type) {
+		case string:
+			key = k
+		case Field:
+			fields = append(fields, k)
+			i--
+			continue
+		default:
+			key = fmt.Sprint(k)
+		}
+
+		var value interface{}
+		if i+1 < len(kvs) {
+			value = kvs[i+1]
+		}
+		fields = append(fields, Field{Key: key, Value: value})
+	}
+	return fields
+}
+
+// fieldString string form of a field value, errors render as their message
+func fieldString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return val
+	case error:
+		return val.Error()
+	case fmt.Stringer:
+		return val.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// fieldJSONValue value suitable for encoding/json, errors and stringers
+// would otherwise be encoded as empty objects. Values marshaling themselves,
+// eg: time.Time, keep their json form.
+func fieldJSONValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+		return v
+	case error:
+		return val.Error()
+	case fmt.Stringer:
+		return val.String()
+	}
+	return v
+}
+
+// quoteFieldValue quote and escape values containing spaces, quotes,
+// '=' or control chars so the k=v output stays parseable
+func quoteFieldValue(s string) string {
+	if needsQuote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func needsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, c := range s {
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f || c == utf8.RuneError {
+			return true
+		}
+	}
+	return false
+}
+
+// formatFields render fields as " k=v k=v", empty for no fields
+func formatFields(fields []Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	for _, f := range fields {
+		buf.WriteByte(' ')
+		buf.WriteString(f.String())
+	}
+	return buf.String()
+}
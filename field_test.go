@@ -0,0 +1,65 @@
+package log4go
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestQuoteFieldValue(t *testing.T) {
+	tests := []struct {
+		value string
+		quote bool
+		want  string
+	}{
+		{"plain", false, "plain"},
+		{"日志", false, "日志"},
+		{"a/b:c,d", false, "a/b:c,d"},
+		{"", true, `""`},
+		{"two words", true, `"two words"`},
+		{"tab\there", true, `"tab\there"`},
+		{"line\nbreak", true, `"line\nbreak"`},
+		{`say "hi"`, true, `"say \"hi\""`},
+		{"a=b", true, `"a=b"`},
+		{"del\x7f", true, `"del\x7f"`},
+		{"bad\xffutf8", true, `"bad\xffutf8"`},
+	}
+
+	for _, tt := range tests {
+		if got := needsQuote(tt.value); got != tt.quote {
+			t.Errorf("needsQuote(%q) = %v, want %v", tt.value, got, tt.quote)
+		}
+		if got := quoteFieldValue(tt.value); got != tt.want {
+			t.Errorf("quoteFieldValue(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLogfmtKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"user_id", "user_id"},
+		{"http.status", "http.status"},
+		{"用户", "用户"},
+		{"user id", "user_id"},
+		{"a=b", "a_b"},
+		{`"quoted"`, "_quoted_"},
+		{"multi\nline", "multi_line"},
+		{"\t", "_"},
+		{"bad\xffkey", "bad�key"},
+	}
+
+	for _, tt := range tests {
+		if got := logfmtKey(tt.key); got != tt.want {
+			t.Errorf("logfmtKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+
+	// empty keys are left out instead of writing "=value"
+	var buf bytes.Buffer
+	writeLogfmtPair(&buf, "", "value")
+	if buf.Len() != 0 {
+		t.Errorf("writeLogfmtPair with an empty key wrote %q", buf.String())
+	}
+}
@@ -0,0 +1,145 @@
+package log4go
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// Compressor compression applied to closed log files
+type Compressor struct {
+	Ext       string // suffix appended to the compressed file, eg: .gz
+	NewWriter func(io.Writer) (io.WriteCloser, error)
+}
+
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[string]*Compressor{
+		"gzip": {
+			Ext: ".gz",
+			NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+				return gzip.NewWriter(w), nil
+			},
+		},
+	}
+)
+
+/*
+RegisterCompressor make a compression available to SetCompression, gzip
+is built in. zstd can be added without this package depending on it:
+
+	log4go.RegisterCompressor("zstd", ".zst", func(w io.Writer) (io.WriteCloser, error) {
+		return zstd.NewWriter(w)
+	})
+*/
+func RegisterCompressor(name, ext string, newWriter func(io.Writer) (io.WriteCloser, error)) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	compressors[name] = &Compressor{Ext: ext, NewWriter: newWriter}
+}
+
+// compressorExts suffixes of all registered compressors
+func compressorExts() []string {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+	exts := make([]string, 0, len(compressors))
+	for _, c := range compressors {
+		exts = append(exts, c.Ext)
+	}
+	return exts
+}
+
+// SetCompression compress every file in the background once it is closed
+// by rotation, name is "gzip" or a registered compressor, "" disables
+func (w *FileWriter) SetCompression(name string) error {
+	if name == "" {
+		w.compressor = nil
+		return nil
+	}
+
+	compressorsMu.RLock()
+	c, ok := compressors[name]
+	compressorsMu.RUnlock()
+	if !ok {
+		return errors.New("Unknown compression (" + name + ")")
+	}
+	w.compressor = c
+	return nil
+}
+
+// errFileInUse a process sharing the file still has it open
+var errFileInUse = errors.New("file in use")
+
+// compressFile write src compressed to a temp name, rename it to src+ext
+// once complete, then remove src. Report false without error when another
+// process sharing the file compressed it already, errFileInUse while a
+// process still writes it. Files are locked in shared mode only, a lock
+// on a read only file fails on some network file systems.
+func compressFile(src string, c *Compressor, shared bool) (done bool, err error) {
+	in, err := os.Open(src)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer in.Close()
+
+	if shared {
+		// processes sharing the file hold a shared lock while they have it
+		// open, and compress it only once
+		locked, err := tryLockFile(in)
+		if err != nil {
+			return false, err
+		}
+		if !locked {
+			return false, errFileInUse
+		}
+		defer unlockFile(in)
+	}
+	fi, err := in.Stat()
+	if err != nil {
+		return false, err
+	}
+	if cur, err := os.Stat(src); err != nil || !os.SameFile(cur, fi) {
+		return false, nil
+	}
+
+	dst := src + c.Ext
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode())
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			out.Close()
+			os.Remove(tmp)
+		}
+	}()
+
+	cw, err := c.NewWriter(out)
+	if err != nil {
+		return false, err
+	}
+	if _, err = io.Copy(cw, in); err != nil {
+		return false, err
+	}
+	if err = cw.Close(); err != nil {
+		return false, err
+	}
+	if err = out.Sync(); err != nil {
+		return false, err
+	}
+	if err = out.Close(); err != nil {
+		return false, err
+	}
+	// keep the original time so max age retention sees the log period
+	os.Chtimes(tmp, fi.ModTime(), fi.ModTime())
+	if err = os.Rename(tmp, dst); err != nil {
+		return false, err
+	}
+	return true, os.Remove(src)
+}
@@ -0,0 +1,179 @@
+package log4go
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// DefaultFileHeader template of SetHeader, each line starts with #
+const DefaultFileHeader = `# log4go file opened {{.OpenTime.Format "2006-01-02T15:04:05.000Z07:00"}}
+# host={{.Hostname}} pid={{.PID}} program={{.Program}} started={{.StartTime.Format "2006-01-02T15:04:05Z07:00"}}
+# version={{.Version}} revision={{.Revision}} go={{.GoVersion}}
+# config:{{.Config}}
+`
+
+var (
+	processStart = time.Now()
+	buildOnce    sync.Once
+	buildVersion string
+	buildVCS     string
+)
+
+// FileHeader information available to the header template
+type FileHeader struct {
+	Path      string    // path of the new file
+	OpenTime  time.Time // when the file was opened
+	Hostname  string
+	PID       int
+	Program   string
+	StartTime time.Time // when the process started
+	Version   string    // main module version from the build info
+	Revision  string    // vcs revision from the build info, with +dirty if modified
+	GoVersion string
+	Config    string // settings of the file writer as " k=v k=v"
+}
+
+// SetHeader write DefaultFileHeader at the top of every new file
+func (w *FileWriter) SetHeader(enabled bool) {
+	w.header = nil
+	if enabled {
+		w.header = template.Must(template.New("header").Parse(DefaultFileHeader))
+	}
+}
+
+// SetHeaderTemplate write the text/template tmpl, executed with a
+// FileHeader, at the top of every new file, "" disables the header
+func (w *FileWriter) SetHeaderTemplate(tmpl string) error {
+	if tmpl == "" {
+		w.header = nil
+		return nil
+	}
+	t, err := template.New("header").Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	w.header = t
+	return nil
+}
+
+// writeHeader write the header if the opened file is empty, appending to
+// an existing file does not repeat it
+func (f *logFile) writeHeader() {
+	w := f.w
+	if w.header == nil || f.size > 0 {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := w.header.Execute(&buf, w.fileHeader(f.filePath)); err != nil {
+		log.Println(err)
+		return
+	}
+
+	var n int
+	var err error
+	if w.shared {
+		// only the first process opening the file writes it
+		if err = lockFile(f.mutex); err != nil {
+			log.Println(err)
+			return
+		}
+		defer unlockFile(f.mutex)
+		if f.refreshSize(); f.size > 0 {
+			return
+		}
+		n, err = f.file.Write(buf.Bytes())
+	} else {
+		n, err = f.fileBufWriter.Write(buf.Bytes())
+	}
+	f.size += int64(n)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func (w *FileWriter) fileHeader(filePath string) *FileHeader {
+	buildOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		buildVersion = info.Main.Version
+		dirty := false
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				buildVCS = s.Value
+			case "vcs.modified":
+				dirty = s.Value == "true"
+			}
+		}
+		if dirty && buildVCS != "" {
+			buildVCS += "+dirty"
+		}
+	})
+
+	h := &FileHeader{
+		Path:      filePath,
+		OpenTime:  time.Now(),
+		PID:       os.Getpid(),
+		Program:   filepath.Base(os.Args[0]),
+		StartTime: processStart,
+		Version:   buildVersion,
+		Revision:  buildVCS,
+		GoVersion: runtime.Version(),
+		Config:    formatFields(w.configFields()),
+	}
+	h.Hostname, _ = os.Hostname()
+	return h
+}
+
+// configFields settings of the writer that differ from the defaults
+func (w *FileWriter) configFields() []Field {
+	fields := []Field{{Key: "level", Value: LEVEL_FLAGS[w.level]}}
+	patterns := make([]string, 0, len(w.files))
+	for _, f := range w.files {
+		patterns = append(patterns, f.pattern)
+	}
+	fields = append(fields, Field{Key: "path", Value: strings.Join(patterns, ",")})
+
+	if w.maxSize > 0 {
+		fields = append(fields, Field{Key: "max_size", Value: w.maxSize})
+	}
+	if w.maxBackups > 0 {
+		fields = append(fields, Field{Key: "max_backups", Value: w.maxBackups})
+	}
+	if w.maxAge > 0 {
+		fields = append(fields, Field{Key: "max_age", Value: w.maxAge})
+	}
+	if w.maxTotalSize > 0 {
+		fields = append(fields, Field{Key: "max_total_size", Value: w.maxTotalSize})
+	}
+	if w.compressor != nil {
+		fields = append(fields, Field{Key: "compression", Value: w.compressor.Ext})
+	}
+	if w.location != nil {
+		fields = append(fields, Field{Key: "timezone", Value: w.location.String()})
+	}
+	if w.flushPerRecord {
+		fields = append(fields, Field{Key: "flush_per_record", Value: true})
+	}
+	if w.syncInterval > 0 {
+		fields = append(fields, Field{Key: "sync_interval", Value: w.syncInterval})
+	}
+	if w.syncOnLevel {
+		fields = append(fields, Field{Key: "sync_level", Value: LEVEL_FLAGS[w.syncLevel]})
+	}
+	if w.shared {
+		fields = append(fields, Field{Key: "shared", Value: true})
+	}
+	return fields
+}
@@ -0,0 +1,128 @@
+package log4go
+
+import (
+	"log"
+	"time"
+)
+
+const (
+	// fileInUseRetry delay before compressing again a file another process
+	// sharing it still had open
+	fileInUseRetry = 5 * time.Second
+	// fileCloseTimeout longest wait of Close for background tasks
+	fileCloseTimeout = 30 * time.Second
+)
+
+// RotateEvent a file closed by rotation
+type RotateEvent struct {
+	Path  string    // path of the closed file, with the compression suffix if compressed
+	Start time.Time // start of the time period, or when the file was opened for size rotation
+	End   time.Time // end of the time period, or when the file was rolled by size
+}
+
+// OnRotate call fn for every file closed by rotation, eg: to upload it.
+// Callbacks run one at a time in a background goroutine after the file is
+// compressed and before the retention policy is applied again, so they
+// never stall logging. In shared mode only the process that compressed a
+// file reports it, without compression every process reports it.
+func (w *FileWriter) OnRotate(fn func(RotateEvent)) {
+	w.rotateHooks = append(w.rotateHooks, fn)
+}
+
+// scheduleRotated compress a closed file and report it in the background
+func (w *FileWriter) scheduleRotated(ev RotateEvent) {
+	c, hooks, shared := w.compressor, w.rotateHooks, w.shared
+	if c == nil && len(hooks) == 0 {
+		return
+	}
+
+	path := ev.Path
+	w.setRotatePending(path, true)
+	w.housekeeper.schedule(func() {
+		retry := false
+		defer func() {
+			if !retry {
+				w.setRotatePending(path, false)
+			}
+		}()
+
+		if c != nil {
+			done, err := compressFile(ev.Path, c, shared)
+			if err == errFileInUse {
+				// slower processes follow the path on their next write or
+				// rotation check, Close waits for the retry
+				retry = true
+				w.housekeeper.wg.Add(1)
+				time.AfterFunc(fileInUseRetry, func() {
+					w.scheduleRotated(ev)
+					w.housekeeper.wg.Done()
+				})
+				return
+			}
+			if err != nil {
+				log.Println(err)
+			} else if !done && shared {
+				// compressed and reported by another process
+				return
+			}
+			if done {
+				ev.Path += c.Ext
+				if err := w.applyPerm(ev.Path); err != nil {
+					log.Println(err)
+				}
+			}
+		}
+		for _, fn := range hooks {
+			runRotateHook(fn, ev)
+		}
+	})
+}
+
+func (w *FileWriter) setRotatePending(path string, pending bool) {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+	if !pending {
+		delete(w.pending, path)
+		return
+	}
+	if w.pending == nil {
+		w.pending = make(map[string]bool)
+	}
+	w.pending[path] = true
+}
+
+// rotatePending report whether path is closed but not compressed and
+// reported yet
+func (w *FileWriter) rotatePending(path string) bool {
+	w.pendingMu.Lock()
+	defer w.pendingMu.Unlock()
+	return w.pending[path]
+}
+
+// Close close the files, then wait for the compression and rotate hooks
+// of the closed files, at most fileCloseTimeout. Logger.Close calls it.
+func (w *FileWriter) Close() error {
+	var err error
+	for _, f := range w.files {
+		if e := f.closeFile(); e != nil && err == nil {
+			err = e
+		}
+		if f.mutex != nil {
+			f.mutex.Close()
+			f.mutex = nil
+		}
+	}
+	if !w.housekeeper.wait(fileCloseTimeout) {
+		log.Println("log4go: rotated files still compressing or reporting at close")
+	}
+	return err
+}
+
+func runRotateHook(fn func(RotateEvent), ev RotateEvent) {
+	defer func() {
+		if e := recover(); e != nil {
+			log.Println("log4go: rotate hook panic:", e)
+		}
+	}()
+	fn(ev)
+}
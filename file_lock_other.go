@@ -0,0 +1,25 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package log4go
+
+import (
+	"os"
+)
+
+// lockFile advisory locks are not available, appends rely on O_APPEND only
+func lockFile(file *os.File) error {
+	return nil
+}
+
+func lockFileShared(file *os.File) error {
+	return nil
+}
+
+func tryLockFile(file *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlockFile(file *os.File) error {
+	return nil
+}
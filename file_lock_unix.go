@@ -0,0 +1,35 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package log4go
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile take an exclusive advisory lock on file, shared by every
+// process having the same file open
+func lockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+}
+
+// lockFileShared take a shared advisory lock on file, it only conflicts
+// with exclusive ones
+func lockFileShared(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_SH)
+}
+
+// tryLockFile take an exclusive advisory lock on file without waiting,
+// report false if another open file holds a lock on it
+func tryLockFile(file *os.File) (bool, error) {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
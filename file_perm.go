@@ -0,0 +1,106 @@
+package log4go
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	fileModeDefault os.FileMode = 0644
+	dirModeDefault  os.FileMode = 0755
+)
+
+// SetFileMode permission of the log files, 0644 by default. The mode is
+// applied with chmod, so it is not narrowed by the umask.
+func (w *FileWriter) SetFileMode(mode os.FileMode) {
+	w.fileMode = mode
+}
+
+// SetDirMode permission of the directories created for the log files, 0755 by default
+func (w *FileWriter) SetDirMode(mode os.FileMode) {
+	w.dirMode = mode
+}
+
+// SetOwner chown the log files, and the directories created for them, to uid and gid
+func (w *FileWriter) SetOwner(uid, gid int) {
+	w.uid, w.gid = uid, gid
+	w.ownerSet = true
+}
+
+func (w *FileWriter) filePerm() os.FileMode {
+	if w.fileMode != 0 {
+		return w.fileMode
+	}
+	return fileModeDefault
+}
+
+func (w *FileWriter) dirPerm() os.FileMode {
+	if w.dirMode != 0 {
+		return w.dirMode
+	}
+	return dirModeDefault
+}
+
+// mkdirAll create dir and its missing parents, only the directories
+// created here get the configured mode and owner
+func (w *FileWriter) mkdirAll(dir string) error {
+	var created []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		created = append(created, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	if err := os.MkdirAll(dir, w.dirPerm()); err != nil {
+		return err
+	}
+	for _, d := range created {
+		if w.dirMode != 0 {
+			if err := os.Chmod(d, w.dirMode); err != nil {
+				return err
+			}
+		}
+		if w.ownerSet {
+			if err := os.Chown(d, w.uid, w.gid); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// openLogFile open filePath for appending with the configured mode and owner
+func (w *FileWriter) openLogFile(filePath string, flag int) (*os.File, error) {
+	if err := w.mkdirAll(filepath.Dir(filePath)); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(filePath, flag|os.O_CREATE|os.O_APPEND, w.filePerm())
+	if err != nil {
+		return nil, err
+	}
+	if err = w.applyPerm(filePath); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// applyPerm apply the configured mode and owner to filePath, eg: to a
+// compressed copy, nothing when they were not set
+func (w *FileWriter) applyPerm(filePath string) error {
+	if w.fileMode != 0 {
+		if err := os.Chmod(filePath, w.fileMode); err != nil {
+			return err
+		}
+	}
+	if w.ownerSet {
+		if err := os.Chown(filePath, w.uid, w.gid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package log4go
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	fileRetryMin = time.Second
+	fileRetryMax = time.Minute
+)
+
+// SetFallback write records to out while a file cannot be written, eg:
+// disk full or directory removed. os.Stderr by default, nil drops them.
+func (w *FileWriter) SetFallback(out io.Writer) {
+	w.fallback = out
+	w.fallbackSet = true
+}
+
+// SetFallbackPath write records to a secondary file while a file cannot be written
+func (w *FileWriter) SetFallbackPath(filePath string) error {
+	file, err := w.openLogFile(filePath, os.O_WRONLY)
+	if err != nil {
+		return err
+	}
+	w.SetFallback(file)
+	return nil
+}
+
+// Lost number of records that could be written neither to their file
+// nor to the fallback
+func (w *FileWriter) Lost() uint64 {
+	return atomic.LoadUint64(&w.lost)
+}
+
+func (w *FileWriter) writeFallback(f *logFile, s string) {
+	w.writeFallbackRecords(f, []byte(s), 1)
+}
+
+// writeFallbackRecords write n records to the fallback at once
+func (w *FileWriter) writeFallbackRecords(f *logFile, b []byte, n uint64) {
+	out := w.fallback
+	if !w.fallbackSet {
+		out = os.Stderr
+	}
+	if out != nil {
+		if _, err := out.Write(b); err == nil {
+			f.diverted += n
+			return
+		}
+	}
+	f.dropped += n
+	atomic.AddUint64(&w.lost, n)
+}
+
+// fileSink writer under the buffer of a file, it keeps what a failed
+// flush, where eg: disk full shows up, could not write for degrade
+type fileSink struct {
+	f *logFile
+}
+
+func (s fileSink) Write(p []byte) (int, error) {
+	n, err := s.f.file.Write(p)
+	if err != nil {
+		s.f.unflushed = append(s.f.unflushed[:0], p[n:]...)
+	}
+	return n, err
+}
+
+// degrade drop the failing file and retry to open it with a growing delay,
+// records meanwhile go to the fallback
+func (f *logFile) degrade(err error, now time.Time) {
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.file = nil
+	f.fileBufWriter = nil
+
+	if !f.degraded {
+		f.degraded = true
+		f.backoff = fileRetryMin
+		f.diverted, f.dropped, f.unflushedRecords = 0, 0, 0
+		log.Printf("log4go: cannot write %s, using fallback until it recovers: %v\n", f.name(), err)
+	} else {
+		f.backoff *= 2
+		if f.backoff > fileRetryMax {
+			f.backoff = fileRetryMax
+		}
+	}
+	f.retryAt = now.Add(f.backoff)
+
+	// records still in the buffer when the flush failed
+	if len(f.unflushed) > 0 {
+		n := uint64(bytes.Count(f.unflushed, []byte{'\n'}))
+		if n == 0 {
+			n = 1
+		}
+		f.unflushedRecords += n
+		f.w.writeFallbackRecords(f, f.unflushed, n)
+		f.unflushed = nil
+	}
+}
+
+// recover try to open the file again once the retry delay elapsed,
+// report whether the file is writable
+func (f *logFile) recover(now time.Time) bool {
+	if now.Before(f.retryAt) {
+		return false
+	}
+	if err := f.rotate(now); err != nil {
+		f.degrade(err, now)
+		return false
+	}
+
+	f.degraded = false
+	log.Printf("log4go: %s recovered, %d records written to fallback, %d lost, %d of them unflushed at the failure\n",
+		f.name(), f.diverted, f.dropped, f.unflushedRecords)
+	return true
+}
+
+func (f *logFile) name() string {
+	if f.filePath != "" {
+		return f.filePath
+	}
+	return f.pathFmt
+}
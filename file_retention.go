@@ -0,0 +1,245 @@
+package log4go
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileHousekeeper runs tasks on rotated files, eg: retention cleanup,
+// in its own goroutine so they never block the logging goroutine
+type fileHousekeeper struct {
+	once  sync.Once
+	mu    sync.Mutex
+	tasks []func()
+	wake  chan struct{}
+	wg    sync.WaitGroup // queued and running tasks, and retries waiting to be queued
+}
+
+// schedule queue f, the goroutine is started on first use
+func (h *fileHousekeeper) schedule(f func()) {
+	h.once.Do(func() {
+		h.wake = make(chan struct{}, 1)
+		go h.run()
+	})
+
+	h.wg.Add(1)
+	h.mu.Lock()
+	h.tasks = append(h.tasks, f)
+	h.mu.Unlock()
+
+	select {
+	case h.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (h *fileHousekeeper) run() {
+	for range h.wake {
+		h.mu.Lock()
+		tasks := h.tasks
+		h.tasks = nil
+		h.mu.Unlock()
+
+		for _, f := range tasks {
+			f()
+			h.wg.Done()
+		}
+	}
+}
+
+// wait until every task is done, false if some are left after timeout
+func (h *fileHousekeeper) wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// SetMaxBackups keep at most n files generated by the path pattern
+// besides the opened one, 0 keeps all
+func (w *FileWriter) SetMaxBackups(n int) {
+	w.maxBackups = n
+}
+
+// SetMaxAge delete files generated by the path pattern not modified
+// within d, 0 keeps all
+func (w *FileWriter) SetMaxAge(d time.Duration) {
+	w.maxAge = d
+}
+
+// SetMaxTotalSize delete the oldest files generated by the path pattern
+// until all of them, the opened one included, fit in size bytes, 0 disables
+func (w *FileWriter) SetMaxTotalSize(size int64) {
+	w.maxTotalSize = size
+}
+
+func (w *FileWriter) retentionEnabled() bool {
+	return w.maxBackups > 0 || w.maxAge > 0 || w.maxTotalSize > 0
+}
+
+// scheduleCleanup apply the retention policy to the files generated by
+// the pattern of f in the background, the opened file is never removed
+func (f *logFile) scheduleCleanup() {
+	w := f.w
+	if !w.retentionEnabled() {
+		return
+	}
+	pathFmt, activePath := filepath.Clean(f.pathFmt), filepath.Clean(f.filePath)
+	maxBackups, maxAge, maxTotalSize := w.maxBackups, w.maxAge, w.maxTotalSize
+	backupTimeFormat := w.backupTimeFormat
+
+	w.housekeeper.schedule(func() {
+		files, err := rotatedFiles(pathFmt, activePath, backupTimeFormat)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+
+		var activeSize int64
+		if fi, err := os.Stat(activePath); err == nil {
+			activeSize = fi.Size()
+		}
+
+		for _, rf := range expiredFiles(files, time.Now(), activeSize, maxBackups, maxAge, maxTotalSize) {
+			if w.rotatePending(rf.path) {
+				// removed by a later cleanup once compressed and reported
+				continue
+			}
+			if err := os.Remove(rf.path); err != nil && !os.IsNotExist(err) {
+				log.Println(err)
+			}
+		}
+	})
+}
+
+// expiredFiles files past the retention limits, files are newest first.
+// Once the total size cap is exceeded the file and every older one expire,
+// so the newest backups are the ones kept.
+func expiredFiles(files []rotatedFile, now time.Time, activeSize int64,
+	maxBackups int, maxAge time.Duration, maxTotalSize int64) []rotatedFile {
+	var expired []rotatedFile
+	total, overSize := activeSize, false
+	for i, rf := range files {
+		if maxTotalSize > 0 && !overSize {
+			total += rf.info.Size()
+			overSize = total > maxTotalSize
+		}
+		if overSize ||
+			(maxBackups > 0 && i >= maxBackups) ||
+			(maxAge > 0 && now.Sub(rf.info.ModTime()) > maxAge) {
+			expired = append(expired, rf)
+		}
+	}
+	return expired
+}
+
+type rotatedFile struct {
+	path string
+	info os.FileInfo
+}
+
+// rotatedFiles files generated by pathFmt, including size rolled backups,
+// except activePath, newest first
+func rotatedFiles(pathFmt, activePath, backupTimeFormat string) ([]rotatedFile, error) {
+	glob, matcher, err := pathFmtMatcher(pathFmt, backupTimeFormat, compressorExts())
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(glob)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]rotatedFile, 0, len(paths))
+	for _, p := range paths {
+		// a .tmp file is being compressed, maybe by another process
+		if p == activePath || !matcher.MatchString(p) || strings.HasSuffix(p, ".tmp") {
+			continue
+		}
+		// Lstat, a symlink such as the one of SetSymlink is not a log file
+		fi, err := os.Lstat(p)
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, rotatedFile{path: p, info: fi})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].info.ModTime().After(files[j].info.ModTime())
+	})
+	return files, nil
+}
+
+// pathFmtMatcher glob and regexp matching every path pathFmt may produce,
+// followed by the optional suffixes this package adds: a backup time in
+// backupTimeFormat, a backup number and one of exts, eg: .3.gz. Other
+// files such as app.log.bak never match.
+func pathFmtMatcher(pathFmt, backupTimeFormat string, exts []string) (string, *regexp.Regexp, error) {
+	verbs := regexp.MustCompile(`%0?\d*d`)
+	literals := verbs.Split(pathFmt, -1)
+
+	globParts := make([]string, len(literals))
+	exprParts := make([]string, len(literals))
+	for i, l := range literals {
+		globParts[i] = l
+		exprParts[i] = regexp.QuoteMeta(l)
+	}
+
+	suffix := ""
+	if backupTimeFormat != "" {
+		suffix += `(\.` + layoutExpr(backupTimeFormat) + `)?`
+	}
+	suffix += `(\.\d+)?`
+	if len(exts) > 0 {
+		quoted := make([]string, len(exts))
+		for i, ext := range exts {
+			quoted[i] = regexp.QuoteMeta(ext)
+		}
+		suffix += "(" + strings.Join(quoted, "|") + ")?"
+	}
+
+	glob := strings.Join(globParts, "*") + "*"
+	matcher, err := regexp.Compile("^" + strings.Join(exprParts, `\d+`) + suffix + "$")
+	return glob, matcher, err
+}
+
+// layoutExpr regexp of the strings a go time layout produces, digit and
+// letter runs of a formatted sample become classes, eg: 20060102 -> \d+
+func layoutExpr(layout string) string {
+	// a zone with an offset, Z07:00 renders UTC as a bare Z
+	sample := time.Date(2006, 1, 2, 15, 4, 5, 0, time.FixedZone("MST", -7*3600)).Format(layout)
+	classes := regexp.MustCompile(`\d+|[A-Za-z]+`)
+
+	// zone offsets have either sign
+	literal := func(s string) string {
+		return strings.NewReplacer("-", "[-+]", `\+`, "[-+]").Replace(regexp.QuoteMeta(s))
+	}
+
+	var expr bytes.Buffer
+	last := 0
+	for _, m := range classes.FindAllStringIndex(sample, -1) {
+		expr.WriteString(literal(sample[last:m[0]]))
+		if c := sample[m[0]]; c >= '0' && c <= '9' {
+			expr.WriteString(`\d+`)
+		} else {
+			expr.WriteString(`[A-Za-z]+`)
+		}
+		last = m[1]
+	}
+	expr.WriteString(literal(sample[last:]))
+	return expr.String()
+}
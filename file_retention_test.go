@@ -0,0 +1,150 @@
+package log4go
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPathFmtMatcher(t *testing.T) {
+	tests := []struct {
+		pathFmt          string
+		backupTimeFormat string
+		path             string
+		match            bool
+	}{
+		{"/logs/app.log", "", "/logs/app.log", true},
+		{"/logs/app.log", "", "/logs/app.log.1", true},
+		{"/logs/app.log", "", "/logs/app.log.12.gz", true},
+		{"/logs/app.log", "", "/logs/app.log.gz", true},
+		{"/logs/app.log", "", "/logs/app.log.bak", false},
+		{"/logs/app.log", "", "/logs/app.log.fallback", false},
+		{"/logs/app.log", "", "/logs/app.log.1.bak", false},
+		{"/logs/app.log", "", "/logs/app.log.gz.tmp", false},
+		{"/logs/app.log", "", "/logs/app.logx", false},
+		{"/logs/app-%04d%02d%02d.log", "", "/logs/app-20240103.log", true},
+		{"/logs/app-%04d%02d%02d.log", "", "/logs/app-20240103.log.2.gz", true},
+		{"/logs/app-%04d%02d%02d.log", "", "/logs/app-2024.log", true},
+		{"/logs/app-%04d%02d%02d.log", "", "/logs/app-x.log", false},
+		{"/logs/app-%04d%02d%02d.log", "", "/logs/other-20240103.log", false},
+		{"/logs/app.log", "20060102-150405", "/logs/app.log.20240103-101500", true},
+		{"/logs/app.log", "20060102-150405", "/logs/app.log.20240103-101500.2.gz", true},
+		{"/logs/app.log", "20060102-150405", "/logs/app.log.2024-01-03", false},
+		{"/logs/app.log", "2006-01-02T15:04:05Z07:00", "/logs/app.log.2024-01-03T10:15:00+08:00", true},
+		{"/logs/app.log", "Jan02", "/logs/app.log.Mar14.gz", true},
+		{"/logs/app.log", "Jan02", "/logs/app.log.bak", false},
+	}
+
+	for _, tt := range tests {
+		_, matcher, err := pathFmtMatcher(tt.pathFmt, tt.backupTimeFormat, []string{".gz", ".zst"})
+		if err != nil {
+			t.Fatalf("pathFmtMatcher(%q, %q): %v", tt.pathFmt, tt.backupTimeFormat, err)
+		}
+		if got := matcher.MatchString(tt.path); got != tt.match {
+			t.Errorf("pathFmtMatcher(%q, %q) match %q = %v, want %v",
+				tt.pathFmt, tt.backupTimeFormat, tt.path, got, tt.match)
+		}
+	}
+}
+
+func TestRotatedFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log4go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	active := filepath.Join(dir, "app-20240101.log")
+	files := map[string]time.Duration{ // name: age
+		"app-20240101.log":          0,
+		"app-20231231.log":          time.Hour,
+		"app-20240101.log.1":        2 * time.Hour,
+		"app-20231230.log.gz":       3 * time.Hour,
+		"app-20240101.log.gz.tmp":   time.Hour,
+		"app-20240101.log.bak":      time.Hour,
+		"app-20240101.log.fallback": time.Hour,
+		"other-20240101.log":        time.Hour,
+	}
+	// newest first, without the active file
+	want := []string{"app-20231231.log", "app-20240101.log.1", "app-20231230.log.gz"}
+
+	now := time.Now()
+	for name, age := range files {
+		p := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(p, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := now.Add(-age)
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(active, filepath.Join(dir, "app-current.log")); err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := rotatedFiles(filepath.Join(dir, "app-%04d%02d%02d.log"), active, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, len(rotated))
+	for i, f := range rotated {
+		got[i] = filepath.Base(f.path)
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("rotatedFiles = %v, want %v", got, want)
+	}
+}
+
+type testFileInfo struct {
+	os.FileInfo
+	size    int64
+	modTime time.Time
+}
+
+func (fi testFileInfo) Size() int64        { return fi.size }
+func (fi testFileInfo) ModTime() time.Time { return fi.modTime }
+
+func TestExpiredFiles(t *testing.T) {
+	now := time.Now()
+	// newest first: name size age
+	files := []rotatedFile{
+		{"a.1", testFileInfo{size: 10, modTime: now.Add(-1 * time.Hour)}},
+		{"a.2", testFileInfo{size: 50, modTime: now.Add(-2 * time.Hour)}},
+		{"a.3", testFileInfo{size: 5, modTime: now.Add(-3 * time.Hour)}},
+		{"a.4", testFileInfo{size: 5, modTime: now.Add(-4 * time.Hour)}},
+	}
+
+	tests := []struct {
+		activeSize   int64
+		maxBackups   int
+		maxAge       time.Duration
+		maxTotalSize int64
+		want         string
+	}{
+		{0, 0, 0, 0, ""},
+		{0, 2, 0, 0, "a.3 a.4"},
+		{0, 0, 150 * time.Minute, 0, "a.3 a.4"},
+		// a large newer backup past the cap takes every older one with it
+		{0, 0, 0, 40, "a.2 a.3 a.4"},
+		{0, 0, 0, 65, "a.4"},
+		{0, 0, 0, 70, ""},
+		{60, 0, 0, 65, "a.1 a.2 a.3 a.4"},
+		{0, 1, 0, 100, "a.2 a.3 a.4"},
+	}
+
+	for _, tt := range tests {
+		expired := expiredFiles(files, now, tt.activeSize, tt.maxBackups, tt.maxAge, tt.maxTotalSize)
+		got := make([]string, len(expired))
+		for i, f := range expired {
+			got[i] = f.path
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("expiredFiles(active %d, backups %d, age %s, total %d) = %v, want %s",
+				tt.activeSize, tt.maxBackups, tt.maxAge, tt.maxTotalSize, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package log4go
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SetShared make the file writer safe when several processes append to
+// the same path: every record is written with a single unbuffered write
+// on the O_APPEND file, so records are never split by another process'
+// output, and size rotation is coordinated with an advisory lock so only
+// one process renames and compresses the file. A process follows the path
+// before every write once another one rotated the file, and a rotated file
+// is compressed only when no process has it open anymore. Records are then
+// written one syscall each, buffer size and flush per record have no effect.
+func (w *FileWriter) SetShared(shared bool) {
+	w.shared = shared
+}
+
+// SetLockWrites also hold an advisory lock on the file around every write
+// in shared mode, for file systems where O_APPEND writes may interleave
+func (w *FileWriter) SetLockWrites(lock bool) {
+	w.lockWrites = lock
+}
+
+// writeShared append s with one write call, the size is refreshed first
+// since other processes grow the file too
+func (f *logFile) writeShared(s string) (int, error) {
+	if f.w.lockWrites {
+		if err := lockFile(f.mutex); err != nil {
+			return 0, err
+		}
+		defer unlockFile(f.mutex)
+	}
+	return f.file.WriteString(s)
+}
+
+// followShared reopen the path when another process rolled the file away,
+// records written to the old file would end up in a backup
+func (f *logFile) followShared() error {
+	if f.fileExists() {
+		return nil
+	}
+	return f.reopen()
+}
+
+// openShared mark the opened file in use with a shared lock, compression
+// waits until no process holds one, and open the lock file of the pattern
+// which serializes rolls and writes. The data file itself cannot serve
+// for them since its shared locks would block any exclusive one.
+func (f *logFile) openShared() error {
+	if err := lockFileShared(f.file); err != nil {
+		return err
+	}
+
+	mutexPath := filepath.Join(filepath.Dir(f.filePath), "."+filepath.Base(f.pattern)+".lock")
+	if f.mutex != nil && f.mutexPath == mutexPath {
+		return nil
+	}
+	mutex, err := f.w.openLogFile(mutexPath, os.O_RDWR)
+	if err != nil {
+		return err
+	}
+	if f.mutex != nil {
+		f.mutex.Close()
+	}
+	f.mutex, f.mutexPath = mutex, mutexPath
+	return nil
+}
+
+// refreshSize size of the file including what other processes appended
+func (f *logFile) refreshSize() {
+	if fi, err := f.file.Stat(); err == nil {
+		f.size = fi.Size()
+	}
+}
+
+// rollShared roll the file by size unless another process did it while
+// this one waited for the lock, then just follow to the new file. The lock
+// is held until the new file is opened.
+func (f *logFile) rollShared() error {
+	mutex := f.mutex
+	if err := lockFile(mutex); err != nil {
+		return err
+	}
+	defer unlockFile(mutex)
+
+	filePath := f.filePath
+	if f.fileExists() {
+		backup := f.w.backupPath(filePath)
+		if err := os.Rename(filePath, backup); err != nil {
+			return err
+		}
+		f.w.scheduleRotated(RotateEvent{Path: backup, Start: f.openedAt, End: time.Now()})
+	}
+	return f.reopen()
+}
@@ -0,0 +1,75 @@
+package log4go
+
+import (
+	"time"
+)
+
+const fileBufSizeDefault = 8192
+
+// SetBufferSize size of the buffer in front of each file, the buffer is
+// flushed by the logger every second unless flushed per record
+func (w *FileWriter) SetBufferSize(size int) {
+	w.bufSize = size
+}
+
+// SetFlushPerRecord hand every record to the operating system as soon as
+// it is written, records survive a crash of the process but not of the host
+func (w *FileWriter) SetFlushPerRecord(flush bool) {
+	w.flushPerRecord = flush
+}
+
+// SetSyncInterval fsync the files at most every d, checked on write and on
+// the periodic flush of the logger every second, so once logging goes quiet
+// the last records wait up to max(d, 1s) for their fsync, 0 disables
+func (w *FileWriter) SetSyncInterval(d time.Duration) {
+	w.syncInterval = d
+}
+
+// SetSyncLevel flush and fsync the file after every record at or above
+// level, eg: ERROR for audit trails, records below it keep the other policy
+func (w *FileWriter) SetSyncLevel(level int) {
+	w.syncLevel = level
+	w.syncOnLevel = true
+}
+
+func (w *FileWriter) bufferSize() int {
+	if w.bufSize > 0 {
+		return w.bufSize
+	}
+	return fileBufSizeDefault
+}
+
+// afterWrite apply the durability policy once a record at level was written
+func (f *logFile) afterWrite(level int, now time.Time) error {
+	w := f.w
+	if w.syncOnLevel && level >= w.syncLevel {
+		return f.sync(now)
+	}
+	if w.syncInterval > 0 && now.Sub(f.lastSync) >= w.syncInterval {
+		return f.sync(now)
+	}
+	if w.flushPerRecord {
+		return f.fileBufWriter.Flush()
+	}
+	return nil
+}
+
+// flush write out the buffer, fsync too if the sync interval elapsed
+func (f *logFile) flush() error {
+	if f.fileBufWriter == nil {
+		return nil
+	}
+	now := time.Now()
+	if f.w.syncInterval > 0 && now.Sub(f.lastSync) >= f.w.syncInterval {
+		return f.sync(now)
+	}
+	return f.fileBufWriter.Flush()
+}
+
+func (f *logFile) sync(now time.Time) error {
+	if err := f.fileBufWriter.Flush(); err != nil {
+		return err
+	}
+	f.lastSync = now
+	return f.file.Sync()
+}
@@ -5,21 +5,110 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os"
-	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 )
 
-var pathVariableTable map[byte]func(*time.Time) int
+var (
+	pathVariableTable map[byte]func(*time.Time) int
+	pathVariableUnit  map[byte]rotateUnit
+)
+
+// rotateUnit period after which a time variable of the path pattern changes
+type rotateUnit int
+
+const (
+	rotateSecond rotateUnit = iota
+	rotateMinute
+	rotateHour
+	rotateDay
+	rotateWeek
+	rotateMonth
+	rotateYear
+	rotateISOYear
+)
 
 // FileWriter file writer define
 type FileWriter struct {
-	level         int
+	lost      uint64 // records neither in a file nor in the fallback, atomic, first for alignment
+	level     int
+	formatter Formatter
+
+	files  []*logFile                 // every output file
+	routes [len(LEVEL_FLAGS)]*logFile // output file of each level, nil drops a level left out by SetLevelPathPattern
+
+	maxSize          int64  // roll to a backup beyond this size, 0 disables
+	backupTimeFormat string // layout of backup suffix, numbered if empty
+
+	maxBackups   int
+	maxAge       time.Duration
+	maxTotalSize int64
+	housekeeper  fileHousekeeper
+	compressor   *Compressor
+	location     *time.Location // time zone of the path variables, local if nil
+
+	symlink string // stable link to the opened file, eg: error.log
+
+	bufSize        int           // bytes buffered per file, default 8192
+	flushPerRecord bool          // flush the buffer after every record
+	syncInterval   time.Duration // fsync at most this often, 0 disables
+	syncLevel      int           // fsync after records at or above it
+	syncOnLevel    bool          // whether syncLevel is set
+
+	fallback    io.Writer // receives records while a file is unwritable
+	fallbackSet bool      // whether fallback was set, os.Stderr otherwise
+
+	shared     bool // several processes append to the same files
+	lockWrites bool // flock around every write in shared mode
+
+	rotateHooks []func(RotateEvent)
+	pendingMu   sync.Mutex
+	pending     map[string]bool    // closed files not compressed and reported yet
+	header      *template.Template // written at the top of every new file
+
+	fileMode os.FileMode // 0644 if not set
+	dirMode  os.FileMode // 0755 if not set
+	uid, gid int
+	ownerSet bool
+}
+
+// logFile one output file of a FileWriter, with its own buffered writer
+// and rotation state
+type logFile struct {
+	w             *FileWriter
+	levelName     string // level the file is dedicated to, empty for the default file
+	pattern       string // path pattern as set, static variables resolved
 	pathFmt       string
-	file          *os.File
-	fileBufWriter *bufio.Writer
 	actions       []func(*time.Time) int
+	units         []rotateUnit
 	variables     []interface{}
+	nextRotate    time.Time // the time variables change at this instant
+	lastWrite     time.Time // record time of the last write since Rotate, zero if idle
+	periodStart   time.Time // start of the period of the opened file
+	openedAt      time.Time
+	file          *os.File
+	fileBufWriter *bufio.Writer
+	unflushed     []byte   // buffered bytes a failed flush could not write
+	mutex         *os.File // lock file serializing rolls and writes in shared mode
+	mutexPath     string
+	filePath      string // path of the opened file
+	size          int64  // bytes written to the opened file
+	lastSync      time.Time
+
+	degraded bool          // the file is unwritable, records go to the fallback
+	retryAt  time.Time     // next attempt to reopen the file
+	backoff  time.Duration // delay before the next attempt
+	diverted uint64        // records written to the fallback while degraded
+	dropped  uint64        // records lost while degraded
+
+	unflushedRecords uint64 // of diverted and dropped, buffered when the file failed
 }
 
 // NewFileWriter create new file writer
@@ -43,27 +132,207 @@ func NewFileWriterWithLevel(level int) *FileWriter {
 	}
 }
 
-// Init for file writer
+// Init for file writer, fails if a file cannot be opened
 func (w *FileWriter) Init() error {
-	return w.Rotate()
+	if len(w.files) == 0 {
+		return errNoPathPattern
+	}
+	now := time.Now()
+	for _, f := range w.files {
+		if !w.accepts(f) {
+			continue
+		}
+		if err := f.rotate(now); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
+var errNoPathPattern = errors.New("No log path pattern set, call SetPathPattern before use")
+
 // Write for file writer
 func (w *FileWriter) Write(r *Record) error {
 	if r.level < w.level {
 		return nil
 	}
-	if w.fileBufWriter == nil {
-		return errors.New("no opened file")
+	f := w.routes[r.level]
+	if f == nil {
+		if len(w.files) == 0 {
+			return errNoPathPattern
+		}
+		// left out by SetLevelPathPattern
+		return nil
 	}
-	if _, err := w.fileBufWriter.WriteString(r.String()); err != nil {
-		return err
+	var s string
+	if w.formatter != nil {
+		s = w.formatter.Format(r)
+	} else {
+		s = r.String()
+	}
+
+	if f.degraded && !f.recover(r.now) {
+		w.writeFallback(f, s)
+		return nil
+	}
+	if buffered, err := f.write(r, s); err != nil {
+		// degrade hands the unflushed buffer, s included if buffered, to
+		// the fallback
+		f.degrade(err, r.now)
+		if !buffered {
+			w.writeFallback(f, s)
+		}
 	}
 	return nil
 }
 
-// SetPathPattern for file writer
+// write s to the file of its period, buffered reports whether s made it
+// into the buffer of the file
+func (f *logFile) write(r *Record, s string) (buffered bool, err error) {
+	w := f.w
+	// rotate on the record time, so a record lands in the file of its period
+	if len(f.units) > 0 && !r.now.Before(f.nextRotate) {
+		if err = f.rotate(r.now); err != nil {
+			return false, err
+		}
+	}
+	f.lastWrite = r.now
+	if f.fileBufWriter == nil {
+		return false, errors.New("no opened file")
+	}
+	if w.shared {
+		if err = f.followShared(); err != nil {
+			return false, err
+		}
+		if w.maxSize > 0 {
+			f.refreshSize()
+		}
+	}
+	if w.maxSize > 0 && f.size > 0 && f.size+int64(len(s)) > w.maxSize {
+		if err = f.rollBySize(); err != nil {
+			return false, err
+		}
+	}
+
+	var n int
+	switch {
+	case w.shared:
+		n, err = f.writeShared(s)
+	case len(s) > f.fileBufWriter.Available():
+		// flush apart from s, a record larger than the buffer bypasses it
+		if err = f.fileBufWriter.Flush(); err != nil {
+			return false, err
+		}
+		if len(s) > f.fileBufWriter.Available() {
+			n, err = f.file.WriteString(s)
+		} else {
+			n, err = f.fileBufWriter.WriteString(s)
+			buffered = true
+		}
+	default:
+		n, err = f.fileBufWriter.WriteString(s)
+		buffered = true
+	}
+	f.size += int64(n)
+	if err != nil {
+		return false, err
+	}
+	return buffered, f.afterWrite(r.level, r.now)
+}
+
+// SetMaxSize roll the opened file to a backup before it grows beyond
+// size bytes, the check is done on every write. Combinable with the
+// time variables of the path pattern, 0 disables size rotation.
+func (w *FileWriter) SetMaxSize(size int64) {
+	w.maxSize = size
+}
+
+// SetBackupTimeFormat name size rolled backups "<path>.<time>" using the
+// go layout instead of the default "<path>.1", "<path>.2" ... where a
+// higher number is a newer backup
+func (w *FileWriter) SetBackupTimeFormat(layout string) {
+	w.backupTimeFormat = layout
+}
+
+// SetPathPattern for file writer, records of every level are written to
+// the file. Time variables %Y %M %D %H %m %S %W (ISO week) %G (ISO week
+// year) %j (day of year) drive rotation, %W goes with %G rather than %Y
+// which is off around new year, eg: app-%G-W%W.log, %{hostname} %{pid} %{program} %{env:NAME} are
+// resolved once here. With the %L variable each level gets its own file
+// instead, eg: /tmp/logs/%L.log writes info.log, error.log ...
 func (w *FileWriter) SetPathPattern(pattern string) error {
+	if strings.Contains(pattern, "%L") {
+		for lvl := range LEVEL_FLAGS {
+			if err := w.SetLevelPathPattern(lvl, lvl, pattern); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	f, err := w.newLogFile("", pattern)
+	if err != nil {
+		return err
+	}
+	for lvl := range w.routes {
+		w.routes[lvl] = f
+	}
+	w.setFiles()
+	return nil
+}
+
+// SetLevelPathPattern route records from minLevel to maxLevel to their own
+// file, overriding SetPathPattern for those levels. A %L variable in the
+// pattern is replaced by the lower case name of minLevel.
+func (w *FileWriter) SetLevelPathPattern(minLevel, maxLevel int, pattern string) error {
+	if minLevel < DEBUG || maxLevel >= len(LEVEL_FLAGS) || minLevel > maxLevel {
+		return errors.New("Invalid level range (" + strconv.Itoa(minLevel) + ", " + strconv.Itoa(maxLevel) + ")")
+	}
+
+	levelName := strings.ToLower(LEVEL_FLAGS[minLevel])
+	f, err := w.newLogFile(levelName, strings.Replace(pattern, "%L", levelName, -1))
+	if err != nil {
+		return err
+	}
+	for lvl := minLevel; lvl <= maxLevel; lvl++ {
+		w.routes[lvl] = f
+	}
+	w.setFiles()
+	return nil
+}
+
+// setFiles collect the distinct files still referenced by the routes
+func (w *FileWriter) setFiles() {
+	w.files = w.files[:0]
+	for _, f := range w.routes {
+		if f == nil {
+			continue
+		}
+		seen := false
+		for _, g := range w.files {
+			if g == f {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			w.files = append(w.files, f)
+		}
+	}
+}
+
+func (w *FileWriter) newLogFile(levelName, pattern string) (*logFile, error) {
+	f := &logFile{
+		w:         w,
+		levelName: levelName,
+	}
+
+	pattern, err := resolveStaticPathVariables(pattern)
+	if err != nil {
+		return nil, err
+	}
+	f.pattern = pattern
+
 	n := 0
 	for _, c := range pattern {
 		if c == '%' {
@@ -72,12 +341,12 @@ func (w *FileWriter) SetPathPattern(pattern string) error {
 	}
 
 	if n == 0 {
-		w.pathFmt = pattern
-		return nil
+		f.pathFmt = pattern
+		return f, nil
 	}
 
-	w.actions = make([]func(*time.Time) int, 0, n)
-	w.variables = make([]interface{}, n, n)
+	f.actions = make([]func(*time.Time) int, 0, n)
+	f.variables = make([]interface{}, n, n)
 	tmp := []byte(pattern)
 
 	variable := 0
@@ -85,9 +354,10 @@ func (w *FileWriter) SetPathPattern(pattern string) error {
 		if variable == 1 {
 			act, ok := pathVariableTable[c]
 			if !ok {
-				return errors.New("Invalid rotate pattern (" + pattern + ")")
+				return nil, errors.New("Invalid rotate pattern (" + pattern + ")")
 			}
-			w.actions = append(w.actions, act)
+			f.actions = append(f.actions, act)
+			f.units = append(f.units, pathVariableUnit[c])
 			variable = 0
 			continue
 		}
@@ -96,66 +366,327 @@ func (w *FileWriter) SetPathPattern(pattern string) error {
 		}
 	}
 
-	w.pathFmt = convertPatternToFmt(tmp)
+	f.pathFmt = convertPatternToFmt(tmp)
 
-	return nil
+	return f, nil
+}
+
+// SetFormatter set the formatter of the file writer, nil restores the default layout
+func (w *FileWriter) SetFormatter(f Formatter) {
+	w.formatter = f
 }
 
-// Rotate for file writer
+// SetSymlink maintain a symlink at linkPath pointing to the opened file,
+// swapped atomically whenever another file is opened, so "tail -F linkPath"
+// always follows the active file. With per level files use %L in linkPath
+// to get one link per level, eg: /tmp/logs/%L.log
+func (w *FileWriter) SetSymlink(linkPath string) {
+	w.symlink = linkPath
+}
+
+// SetLocation evaluate the time variables of the path pattern, and so
+// the rotation boundaries, in loc, eg: time.UTC. Local time if not set.
+func (w *FileWriter) SetLocation(loc *time.Location) {
+	w.location = loc
+}
+
+// Rotate for file writer, records rotate their file as soon as their time
+// crosses the next boundary of the pattern, the logger calls Rotate
+// periodically to also rotate files idle since the previous call and
+// detect moved files
 func (w *FileWriter) Rotate() error {
 	now := time.Now()
+	for _, f := range w.files {
+		if !w.accepts(f) {
+			continue
+		}
+		if f.degraded {
+			f.recover(now)
+			continue
+		}
+		// records of the ended period may still be queued behind the
+		// ones written since the last call, only rotate an idle file and
+		// leave the others to the time of their next record
+		at := now
+		if !f.lastWrite.IsZero() {
+			at = f.lastWrite
+			f.lastWrite = time.Time{}
+		}
+		if err := f.rotate(at); err != nil {
+			f.degrade(err, now)
+		}
+	}
+	return nil
+}
+
+// Reopen close the opened files and open their paths again, for use
+// after an external tool such as logrotate renamed them
+func (w *FileWriter) Reopen() error {
+	now := time.Now()
+	for _, f := range w.files {
+		if !w.accepts(f) || f.degraded {
+			continue
+		}
+		if err := f.reopen(); err != nil {
+			f.degrade(err, now)
+		}
+	}
+	return nil
+}
+
+// accepts report whether f receives any level at or above the writer
+// level, files of lower levels are never opened
+func (w *FileWriter) accepts(f *logFile) bool {
+	for lvl := w.level; lvl < len(w.routes); lvl++ {
+		if w.routes[lvl] == f {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush for file writer
+func (w *FileWriter) Flush() error {
+	now := time.Now()
+	for _, f := range w.files {
+		if f.degraded {
+			continue
+		}
+		if err := f.flush(); err != nil {
+			f.degrade(err, now)
+		}
+	}
+	return nil
+}
+
+// Level minimum level of the file writer
+func (w *FileWriter) Level() int {
+	return w.level
+}
+
+func (f *logFile) rotate(now time.Time) error {
 	v := 0
 	rotate := false
 
-	for i, act := range w.actions {
+	if f.w.location != nil {
+		now = now.In(f.w.location)
+	} else {
+		now = now.Local()
+	}
+	if len(f.units) > 0 {
+		f.nextRotate = nextRotateBoundary(now, f.units)
+	}
+
+	for i, act := range f.actions {
 		v = act(&now)
-		if v != w.variables[i] {
-			w.variables[i] = v
+		if v != f.variables[i] {
+			f.variables[i] = v
 			rotate = true
 		}
 	}
 
-	if rotate == false {
+	if rotate == false && f.file != nil {
+		// the file was moved or removed by someone else, eg: logrotate
+		if !f.fileExists() {
+			return f.reopen()
+		}
 		return nil
 	}
 
-	if w.fileBufWriter != nil {
-		if err := w.fileBufWriter.Flush(); err != nil {
-			return err
+	closed := RotateEvent{Path: f.filePath, Start: f.periodStart}
+	if err := f.closeFile(); err != nil {
+		return err
+	}
+
+	filePath := fmt.Sprintf(f.pathFmt, f.variables...)
+	f.periodStart = now
+	if len(f.units) > 0 {
+		f.periodStart = rotatePeriodStart(now, f.units)
+	}
+	if closed.Path != "" && closed.Path != filePath {
+		closed.End = f.periodStart
+		f.w.scheduleRotated(closed)
+	}
+	return f.openFile(filePath)
+}
+
+func (f *logFile) reopen() error {
+	if f.filePath == "" {
+		return f.rotate(time.Now())
+	}
+	filePath := f.filePath
+	if err := f.closeFile(); err != nil {
+		return err
+	}
+	return f.openFile(filePath)
+}
+
+// fileExists report whether the path of the opened file still refers to it
+func (f *logFile) fileExists() bool {
+	cur, err := os.Stat(f.filePath)
+	if err != nil {
+		return false
+	}
+	opened, err := f.file.Stat()
+	if err != nil {
+		return false
+	}
+	return os.SameFile(cur, opened)
+}
+
+// rollBySize rename the opened file to its next backup name and reopen the path
+func (f *logFile) rollBySize() error {
+	if f.w.shared {
+		return f.rollShared()
+	}
+	return f.roll()
+}
+
+func (f *logFile) roll() error {
+	filePath := f.filePath
+	if err := f.closeFile(); err != nil {
+		return err
+	}
+	backup := f.w.backupPath(filePath)
+	if err := os.Rename(filePath, backup); err != nil {
+		return err
+	}
+	f.w.scheduleRotated(RotateEvent{Path: backup, Start: f.openedAt, End: time.Now()})
+	return f.openFile(filePath)
+}
+
+func (w *FileWriter) backupPath(filePath string) string {
+	if w.backupTimeFormat != "" {
+		backup := filePath + "." + time.Now().Format(w.backupTimeFormat)
+		if !w.backupExists(backup) {
+			return backup
 		}
+		// several rolls within one time unit, number them
+		filePath = backup
 	}
 
-	if w.file != nil {
-		if err := w.file.Close(); err != nil {
-			return err
+	// one past the highest existing number, so numbers stay increasing
+	// when the retention policy removed the oldest backups
+	n := 0
+	filePath = filepath.Clean(filePath)
+	backups, _ := filepath.Glob(filePath + ".*")
+	for _, b := range backups {
+		suffix := strings.TrimPrefix(b, filePath+".")
+		if i := strings.IndexByte(suffix, '.'); i >= 0 {
+			suffix = suffix[:i]
+		}
+		if v, err := strconv.Atoi(suffix); err == nil && v > n {
+			n = v
+		}
+	}
+	return filePath + "." + strconv.Itoa(n+1)
+}
+
+// backupExists report whether backup exists, compressed or not
+func (w *FileWriter) backupExists(backup string) bool {
+	if _, err := os.Stat(backup); err == nil {
+		return true
+	}
+	if w.compressor != nil {
+		if _, err := os.Stat(backup + w.compressor.Ext); err == nil {
+			return true
 		}
 	}
+	return false
+}
 
-	filePath := fmt.Sprintf(w.pathFmt, w.variables...)
+func (f *logFile) closeFile() error {
+	if f.fileBufWriter != nil {
+		if err := f.fileBufWriter.Flush(); err != nil {
+			return err
+		}
+		f.fileBufWriter = nil
+	}
 
-	if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
-		if !os.IsExist(err) {
+	if f.file != nil {
+		if f.w.syncInterval > 0 || f.w.syncOnLevel {
+			f.file.Sync()
+		}
+		if err := f.file.Close(); err != nil {
 			return err
 		}
+		f.file = nil
 	}
+	return nil
+}
 
-	file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+func (f *logFile) openFile(filePath string) error {
+	file, err := f.w.openLogFile(filePath, os.O_RDWR)
 	if err != nil {
 		return err
 	}
-	w.file = file
+	f.file = file
+	f.filePath = filePath
+	f.openedAt = time.Now()
+	f.size = 0
+	if fi, err := file.Stat(); err == nil {
+		f.size = fi.Size()
+	}
+
+	if f.w.shared {
+		if err := f.openShared(); err != nil {
+			return err
+		}
+	}
 
-	if w.fileBufWriter = bufio.NewWriterSize(w.file, 8192); w.fileBufWriter == nil {
+	f.lastSync = time.Now()
+	f.unflushed = nil
+	if f.fileBufWriter = bufio.NewWriterSize(fileSink{f}, f.w.bufferSize()); f.fileBufWriter == nil {
 		return errors.New("new fileBufWriter failed")
 	}
+	f.writeHeader()
+
+	if link := f.symlinkPath(); link != "" {
+		if err := updateSymlink(link, filePath); err != nil {
+			log.Println(err)
+		} else if f.w.ownerSet {
+			os.Lchown(link, f.w.uid, f.w.gid)
+		}
+	}
 
+	f.scheduleCleanup()
 	return nil
 }
 
-// Flush for file writer
-func (w *FileWriter) Flush() error {
-	if w.fileBufWriter != nil {
-		return w.fileBufWriter.Flush()
+// symlinkPath link of this file, a link without %L belongs to the default file
+func (f *logFile) symlinkPath() string {
+	link := f.w.symlink
+	if !strings.Contains(link, "%L") {
+		if f.levelName != "" {
+			return ""
+		}
+		return link
+	}
+	if f.levelName == "" {
+		return ""
+	}
+	return strings.Replace(link, "%L", f.levelName, -1)
+}
+
+// updateSymlink point linkPath to target by renaming a new link over it,
+// the target is relative when it is in the directory of the link
+func updateSymlink(linkPath, target string) error {
+	if rel, err := filepath.Rel(filepath.Dir(linkPath), target); err == nil && !strings.HasPrefix(rel, "..") {
+		target = rel
+	}
+	if cur, err := os.Readlink(linkPath); err == nil && cur == target {
+		return nil
+	}
+
+	// per process temp name, several processes may share the link
+	tmp := linkPath + "." + strconv.Itoa(os.Getpid()) + ".tmp"
+	os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, linkPath); err != nil {
+		os.Remove(tmp)
+		return err
 	}
 	return nil
 }
@@ -180,20 +711,209 @@ func getMin(now *time.Time) int {
 	return now.Minute()
 }
 
+func getSecond(now *time.Time) int {
+	return now.Second()
+}
+
+func getISOWeek(now *time.Time) int {
+	_, week := now.ISOWeek()
+	return week
+}
+
+func getISOYear(now *time.Time) int {
+	year, _ := now.ISOWeek()
+	return year
+}
+
+// isoYearStart monday of ISO week 1 of year, the week with january 4th
+func isoYearStart(year int, loc *time.Location) time.Time {
+	jan4 := time.Date(year, 1, 4, 12, 0, 0, 0, loc)
+	return wallDate(year, 1, 4-(int(jan4.Weekday())+6)%7, 0, 0, loc)
+}
+
+func getYearDay(now *time.Time) int {
+	return now.YearDay()
+}
+
+// nextRotateBoundary first instant after now at which one of the units changes
+func nextRotateBoundary(now time.Time, units []rotateUnit) time.Time {
+	var next time.Time
+	y, m, d := now.Date()
+	loc := now.Location()
+
+	for _, u := range units {
+		var t time.Time
+		switch u {
+		case rotateSecond:
+			t = now.Truncate(time.Second).Add(time.Second)
+		case rotateMinute:
+			t = wallDate(y, m, d, now.Hour(), now.Minute()+1, loc)
+		case rotateHour:
+			t = wallDate(y, m, d, now.Hour()+1, 0, loc)
+		case rotateDay:
+			t = wallDate(y, m, d+1, 0, 0, loc)
+		case rotateWeek:
+			// ISO weeks start on monday
+			days := (8 - int(now.Weekday())) % 7
+			if days == 0 {
+				days = 7
+			}
+			t = wallDate(y, m, d+days, 0, 0, loc)
+		case rotateMonth:
+			t = wallDate(y, m+1, 1, 0, 0, loc)
+		case rotateYear:
+			t = wallDate(y+1, 1, 1, 0, 0, loc)
+		case rotateISOYear:
+			year, _ := now.ISOWeek()
+			t = isoYearStart(year+1, loc)
+		}
+		if next.IsZero() || t.Before(next) {
+			next = t
+		}
+	}
+	return next
+}
+
+// rotatePeriodStart start of the period containing now, the latest
+// instant before now at which one of the units changed
+func rotatePeriodStart(now time.Time, units []rotateUnit) time.Time {
+	var start time.Time
+	y, m, d := now.Date()
+	loc := now.Location()
+
+	for _, u := range units {
+		var t time.Time
+		switch u {
+		case rotateSecond:
+			t = now.Truncate(time.Second)
+		case rotateMinute:
+			t = wallDate(y, m, d, now.Hour(), now.Minute(), loc)
+		case rotateHour:
+			t = wallDate(y, m, d, now.Hour(), 0, loc)
+		case rotateDay:
+			t = wallDate(y, m, d, 0, 0, loc)
+		case rotateWeek:
+			days := (int(now.Weekday()) + 6) % 7
+			t = wallDate(y, m, d-days, 0, 0, loc)
+		case rotateMonth:
+			t = wallDate(y, m, 1, 0, 0, loc)
+		case rotateYear:
+			t = wallDate(y, 1, 1, 0, 0, loc)
+		case rotateISOYear:
+			year, _ := now.ISOWeek()
+			t = isoYearStart(year, loc)
+		}
+		if t.After(start) {
+			start = t
+		}
+	}
+	return start
+}
+
+// wallDate time.Date of a time on a whole minute, except that a wall
+// time skipped by a daylight saving change, eg: 02:00 when the clocks jump
+// to 03:00, gives the instant of the jump instead of one before it
+func wallDate(year int, month time.Month, day, hour, min int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, hour, min, 0, 0, loc)
+	want := time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	if wallClock(t).Equal(want) {
+		return t
+	}
+
+	// first second whose wall clock reaches want, gaps never last a day
+	lo, hi := t.Add(-24*time.Hour).Unix(), t.Add(24*time.Hour).Unix()
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if wallClock(time.Unix(mid, 0).In(loc)).Before(want) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return time.Unix(hi, 0).In(loc)
+}
+
+// wallClock date and time t shows, as if it were UTC
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// resolveStaticPathVariables replace the variables that never change
+// while the process runs, once when the pattern is set:
+//
+//	%{hostname}    host name
+//	%{pid}         process id
+//	%{program}     program name
+//	%{env:NAME}    environment variable NAME, empty if not set
+func resolveStaticPathVariables(pattern string) (string, error) {
+	var buf bytes.Buffer
+	for {
+		i := strings.Index(pattern, "%{")
+		if i < 0 {
+			buf.WriteString(pattern)
+			return buf.String(), nil
+		}
+		end := strings.IndexByte(pattern[i:], '}')
+		if end < 0 {
+			return "", errors.New("Invalid rotate pattern (" + pattern + "): unclosed %{")
+		}
+
+		name := pattern[i+2 : i+end]
+		var value string
+		switch {
+		case strings.HasPrefix(name, "env:"):
+			value = os.Getenv(name[len("env:"):])
+		case strings.EqualFold(name, "hostname"):
+			value, _ = os.Hostname()
+		case strings.EqualFold(name, "pid"):
+			value = strconv.Itoa(os.Getpid())
+		case strings.EqualFold(name, "program"):
+			value = filepath.Base(os.Args[0])
+		default:
+			return "", errors.New("Invalid rotate pattern (" + pattern + "): unknown variable %{" + name + "}")
+		}
+
+		buf.WriteString(pattern[:i])
+		// a literal % would be taken for a time variable
+		buf.WriteString(strings.Replace(value, "%", "_", -1))
+		pattern = pattern[i+end+1:]
+	}
+}
+
 func convertPatternToFmt(pattern []byte) string {
 	pattern = bytes.Replace(pattern, []byte("%Y"), []byte("%d"), -1)
 	pattern = bytes.Replace(pattern, []byte("%M"), []byte("%02d"), -1)
 	pattern = bytes.Replace(pattern, []byte("%D"), []byte("%02d"), -1)
 	pattern = bytes.Replace(pattern, []byte("%H"), []byte("%02d"), -1)
 	pattern = bytes.Replace(pattern, []byte("%m"), []byte("%02d"), -1)
+	pattern = bytes.Replace(pattern, []byte("%S"), []byte("%02d"), -1)
+	pattern = bytes.Replace(pattern, []byte("%W"), []byte("%02d"), -1)
+	pattern = bytes.Replace(pattern, []byte("%G"), []byte("%d"), -1)
+	pattern = bytes.Replace(pattern, []byte("%j"), []byte("%03d"), -1)
 	return string(pattern)
 }
 
 func init() {
-	pathVariableTable = make(map[byte]func(*time.Time) int, 5)
+	pathVariableTable = make(map[byte]func(*time.Time) int, 8)
 	pathVariableTable['Y'] = getYear
 	pathVariableTable['M'] = getMonth
 	pathVariableTable['D'] = getDay
 	pathVariableTable['H'] = getHour
 	pathVariableTable['m'] = getMin
+	pathVariableTable['S'] = getSecond
+	pathVariableTable['W'] = getISOWeek
+	pathVariableTable['G'] = getISOYear
+	pathVariableTable['j'] = getYearDay
+
+	pathVariableUnit = map[byte]rotateUnit{
+		'Y': rotateYear,
+		'M': rotateMonth,
+		'D': rotateDay,
+		'H': rotateHour,
+		'm': rotateMinute,
+		'S': rotateSecond,
+		'W': rotateWeek,
+		'G': rotateISOYear,
+		'j': rotateDay,
+	}
 }
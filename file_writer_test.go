@@ -0,0 +1,72 @@
+package log4go
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRotateBoundaries(t *testing.T) {
+	const layout = "2006-01-02 15:04:05"
+	tests := []struct {
+		zone  string
+		units []rotateUnit
+		now   string
+		start string
+		next  string
+	}{
+		// wednesday, ISO weeks start on monday
+		{"UTC", []rotateUnit{rotateWeek}, "2024-01-03 10:00:00", "2024-01-01 00:00:00", "2024-01-08 00:00:00"},
+		{"UTC", []rotateUnit{rotateWeek}, "2024-01-07 23:59:59", "2024-01-01 00:00:00", "2024-01-08 00:00:00"},
+		{"UTC", []rotateUnit{rotateWeek}, "2024-01-08 00:00:00", "2024-01-08 00:00:00", "2024-01-15 00:00:00"},
+		// week across months and years
+		{"UTC", []rotateUnit{rotateWeek}, "2024-12-31 12:00:00", "2024-12-30 00:00:00", "2025-01-06 00:00:00"},
+		{"UTC", []rotateUnit{rotateMonth}, "2024-01-31 23:59:59", "2024-01-01 00:00:00", "2024-02-01 00:00:00"},
+		{"UTC", []rotateUnit{rotateMonth}, "2024-02-29 12:00:00", "2024-02-01 00:00:00", "2024-03-01 00:00:00"},
+		{"UTC", []rotateUnit{rotateMonth}, "2024-12-15 12:00:00", "2024-12-01 00:00:00", "2025-01-01 00:00:00"},
+		{"UTC", []rotateUnit{rotateYear}, "2024-12-31 23:59:59", "2024-01-01 00:00:00", "2025-01-01 00:00:00"},
+		{"UTC", []rotateUnit{rotateISOYear}, "2024-12-30 12:00:00", "2024-12-30 00:00:00", "2025-12-29 00:00:00"},
+		{"UTC", []rotateUnit{rotateISOYear}, "2021-01-03 12:00:00", "2019-12-30 00:00:00", "2021-01-04 00:00:00"},
+		// the smallest unit wins
+		{"UTC", []rotateUnit{rotateYear, rotateMonth, rotateDay}, "2024-05-17 08:30:00", "2024-05-17 00:00:00", "2024-05-18 00:00:00"},
+		{"UTC", []rotateUnit{rotateISOYear, rotateWeek}, "2024-12-28 08:30:00", "2024-12-23 00:00:00", "2024-12-30 00:00:00"},
+		// 02:00 does not exist, clocks jump to 03:00
+		{"America/New_York", []rotateUnit{rotateHour}, "2024-03-10 01:30:00", "2024-03-10 01:00:00", "2024-03-10 03:00:00"},
+		{"America/New_York", []rotateUnit{rotateDay}, "2024-03-10 12:00:00", "2024-03-10 00:00:00", "2024-03-11 00:00:00"},
+		{"America/New_York", []rotateUnit{rotateWeek}, "2024-03-09 12:00:00", "2024-03-04 00:00:00", "2024-03-11 00:00:00"},
+		// 01:00 to 02:00 happens twice, the hour variable changes at 02:00 of the second pass
+		{"America/New_York", []rotateUnit{rotateDay}, "2024-11-03 12:00:00", "2024-11-03 00:00:00", "2024-11-04 00:00:00"},
+		// midnight does not exist, the day starts at 01:00
+		{"America/Sao_Paulo", []rotateUnit{rotateDay}, "2018-11-03 12:00:00", "2018-11-03 00:00:00", "2018-11-04 01:00:00"},
+		{"America/Sao_Paulo", []rotateUnit{rotateDay}, "2018-11-04 12:00:00", "2018-11-04 01:00:00", "2018-11-05 00:00:00"},
+	}
+
+	for _, tt := range tests {
+		loc, err := time.LoadLocation(tt.zone)
+		if err != nil {
+			t.Skipf("time zone %s: %v", tt.zone, err)
+		}
+		now, _ := time.ParseInLocation(layout, tt.now, loc)
+		start, _ := time.ParseInLocation(layout, tt.start, loc)
+		next, _ := time.ParseInLocation(layout, tt.next, loc)
+
+		if got := rotatePeriodStart(now, tt.units); !got.Equal(start) {
+			t.Errorf("rotatePeriodStart(%s %s, %v) = %s, want %s", tt.now, tt.zone, tt.units, got, start)
+		}
+		if got := nextRotateBoundary(now, tt.units); !got.Equal(next) {
+			t.Errorf("nextRotateBoundary(%s %s, %v) = %s, want %s", tt.now, tt.zone, tt.units, got, next)
+		}
+	}
+}
+
+func TestRotateBoundaryFallBack(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	// 01:30 EDT, the next 01:xx hour is EST and keeps the hour variable
+	now := time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC).In(loc)
+	want := time.Date(2024, 11, 3, 7, 0, 0, 0, time.UTC)
+	if got := nextRotateBoundary(now, []rotateUnit{rotateHour}); !got.Equal(want) {
+		t.Errorf("nextRotateBoundary(%s) = %s, want %s", now, got, want)
+	}
+}
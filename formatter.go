@@ -0,0 +1,33 @@
+package log4go
+
+// Formatter renders a record as the text a writer outputs, including the
+// trailing newline if one is wanted
+type Formatter interface {
+	Format(*Record) string
+}
+
+// NewFormatter create formatter by name as used in the config file:
+// "" for the writer default, "json", "logfmt", or a PatternLayout conversion pattern
+func NewFormatter(format string) (Formatter, error) {
+	switch format {
+	case "":
+		return nil, nil
+	case "json":
+		return NewJSONFormatter(), nil
+	case "logfmt":
+		return NewLogfmtFormatter(), nil
+	}
+	p, err := NewPatternLayout(format)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// FormatterFunc adapter to use an ordinary function as a Formatter
+type FormatterFunc func(*Record) string
+
+// Format call f(r)
+func (f FormatterFunc) Format(r *Record) string {
+	return f(r)
+}
@@ -0,0 +1,83 @@
+package log4go
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// JSONFormatter renders one JSON object per line, structured fields are
+// inlined as top-level keys. An empty key name omits that key.
+type JSONFormatter struct {
+	TimeKey    string
+	LevelKey   string
+	CallerKey  string
+	MessageKey string
+	TimeLayout string // default time.RFC3339Nano
+}
+
+// NewJSONFormatter create json formatter with keys time, level, caller and msg
+func NewJSONFormatter() *JSONFormatter {
+	return &JSONFormatter{
+		TimeKey:    "time",
+		LevelKey:   "level",
+		CallerKey:  "caller",
+		MessageKey: "msg",
+		TimeLayout: time.RFC3339Nano,
+	}
+}
+
+// Format render r as a single line json object
+func (f *JSONFormatter) Format(r *Record) string {
+	var buf bytes.Buffer
+	keys := make(map[string]bool, 4+len(r.fields))
+
+	buf.WriteByte('{')
+	layout := f.TimeLayout
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+	f.writeKey(&buf, keys, f.TimeKey, r.now.Format(layout))
+	f.writeKey(&buf, keys, f.LevelKey, LEVEL_FLAGS[r.level])
+	f.writeKey(&buf, keys, f.CallerKey, r.code)
+	f.writeKey(&buf, keys, f.MessageKey, r.info)
+
+	// fields never override the keys above or an earlier field
+	for _, field := range r.fields {
+		f.writeKey(&buf, keys, field.Key, fieldJSONValue(field.Value))
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+func (f *JSONFormatter) writeKey(buf *bytes.Buffer, keys map[string]bool, key string, value interface{}) {
+	if key == "" || keys[key] {
+		return
+	}
+	keys[key] = true
+
+	if buf.Len() > 1 {
+		buf.WriteByte(',')
+	}
+	k, _ := marshalJSON(key)
+	buf.Write(k)
+	buf.WriteByte(':')
+
+	v, err := marshalJSON(value)
+	if err != nil {
+		v, _ = marshalJSON(fieldString(value))
+	}
+	buf.Write(v)
+}
+
+// marshalJSON json.Marshal without escaping <, > and & so log lines keep
+// the original chars
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
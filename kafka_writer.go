@@ -123,7 +123,12 @@ func (k *KafKaWriter) Write(r *Record) error {
 	json.Unmarshal(byteData, &structData)
 	delete(structData, "extraFields")
 
-	// not exist new fields will be added
+	// record fields, then extra fields, are added when not exist
+	for _, f := range r.fields {
+		if _, ok := structData[f.Key]; !ok {
+			structData[f.Key] = fieldJSONValue(f.Value)
+		}
+	}
 	for k, v := range data.ExtraFields {
 		if _, ok := structData[k]; !ok {
 			structData[k] = v
@@ -243,3 +248,8 @@ func (k *KafKaWriter) Stop() {
 	<-k.stop
 	k.producer.Close()
 }
+
+// Level minimum level of the kafka writer
+func (k *KafKaWriter) Level() int {
+	return k.level
+}
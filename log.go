@@ -3,10 +3,14 @@ package log4go
 import (
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
 	"path"
 	"runtime"
 	"strconv"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 )
 
@@ -25,17 +29,13 @@ const (
 
 const tunnel_size_default = 1024
 
-type Record struct {
-	time  string
-	code  string
-	info  string
-	level int
-}
-
-func (r *Record) String() string {
-	return fmt.Sprintf("%s [%s] <%s> %s\n", r.time, LEVEL_FLAGS[r.level], r.code, r.info)
-}
-
+// Writer receives every record delivered by the logger goroutine.
+//
+// The *Record passed to Write is only valid until Write returns: once
+// every writer has been called it goes back to a pool and is reused for
+// a later record. A writer that needs the record after returning, e.g.
+// to batch or send it from another goroutine, must keep r.Clone() or
+// copy the values it needs.
 type Writer interface {
 	Init() error
 	Write(*Record) error
@@ -50,16 +50,40 @@ type Flusher interface {
 	Flush() error
 }
 
+// Reopener writer able to close and reopen its output, eg: after an
+// external logrotate moved the file away
+type Reopener interface {
+	Reopen() error
+}
+
+// Closer writer releasing its output when the logger is closed, eg: the
+// file writer waits for the compression of its rotated files
+type Closer interface {
+	Close() error
+}
+
+// Leveler writer exposing its minimum level, used by the logger to
+// drop records no writer would accept before formatting them
+type Leveler interface {
+	Level() int
+}
+
 type Logger struct {
-	writers []Writer
-	tunnel  chan *Record
-	//level       int
+	writers     []Writer
+	tunnel      chan *Record
+	level       int32 // logger-wide minimum level, atomic
+	threshold   int32 // max(level, lowest writer level), atomic fast-path
 	lastTime    int64
 	lastTimeStr string
 	c           chan bool
+	reopen      chan bool
+	signals     chan os.Signal
 	layout      string
 
 	fullPath bool // show full path, default only show file:line_number
+
+	parent *Logger // logger owning writers and tunnel, nil for a root logger
+	fields []Field // fields attached to every record of this logger
 }
 
 func NewLogger() *Logger {
@@ -72,7 +96,8 @@ func NewLogger() *Logger {
 	l.writers = make([]Writer, 0, 2)
 	l.tunnel = make(chan *Record, tunnel_size_default)
 	l.c = make(chan bool, 1)
-	//l.level = DEBUG
+	l.reopen = make(chan bool, 1)
+	l.level = DEBUG
 	l.layout = "2006/01/02 15:04:05"
 
 	go boostrapLogWriter(l)
@@ -81,18 +106,72 @@ func NewLogger() *Logger {
 }
 
 func (l *Logger) Register(w Writer) {
+	l = l.root()
 	if err := w.Init(); err != nil {
 		panic(err)
 	}
 	l.writers = append(l.writers, w)
+	l.updateThreshold()
 }
 
+// SetLevel set the logger-wide minimum level, records below it are
+// dropped before formatting regardless of the writers' levels
 func (l *Logger) SetLevel(lvl int) {
-	//l.level = lvl
+	l = l.root()
+	atomic.StoreInt32(&l.level, int32(lvl))
+	l.updateThreshold()
+}
+
+// Enabled report whether a record at level would reach any writer,
+// use it to guard expensive argument construction
+func (l *Logger) Enabled(level int) bool {
+	return int32(level) >= atomic.LoadInt32(&l.root().threshold)
+}
+
+// updateThreshold recompute the fast-path level from the logger level
+// and the lowest level among registered writers, writers not
+// implementing Leveler accept every level
+func (l *Logger) updateThreshold() {
+	threshold := atomic.LoadInt32(&l.level)
+	if len(l.writers) > 0 {
+		lowest := int32(FATAL)
+		for _, w := range l.writers {
+			lvl := int32(DEBUG)
+			if lw, ok := w.(Leveler); ok {
+				lvl = int32(lw.Level())
+			}
+			if lvl < lowest {
+				lowest = lvl
+			}
+		}
+		if lowest > threshold {
+			threshold = lowest
+		}
+	}
+	atomic.StoreInt32(&l.threshold, threshold)
 }
 
 func (l *Logger) SetLayout(layout string) {
-	l.layout = layout
+	l.root().layout = layout
+}
+
+// With create a child logger carrying the given key/value pairs,
+// the child shares writers with its parent
+func (l *Logger) With(kvs ...interface{}) *Logger {
+	fields := make([]Field, 0, len(l.fields)+len(kvs)/2)
+	fields = append(fields, l.fields...)
+	fields = append(fields, makeFields(kvs)...)
+	return &Logger{
+		parent: l.root(),
+		fields: fields,
+	}
+}
+
+func (l *Logger) root() *Logger {
+	if l.parent != nil {
+		return l.parent
+	}
+	return l
 }
 
 func (l *Logger) Debug(fmt string, args ...interface{}) {
@@ -116,6 +195,14 @@ func (l *Logger) Fatal(fmt string, args ...interface{}) {
 }
 
 func (l *Logger) Close() {
+	l = l.root()
+	if l.signals != nil {
+		// no more signals are delivered once Stop returns, closing ends
+		// the goroutine of ReopenOnSignal
+		signal.Stop(l.signals)
+		close(l.signals)
+		l.signals = nil
+	}
 	close(l.tunnel)
 	<-l.c
 
@@ -125,15 +212,49 @@ func (l *Logger) Close() {
 				log.Println(err)
 			}
 		}
+		if c, ok := w.(Closer); ok {
+			if err := c.Close(); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
+
+// Reopen ask the logger goroutine to reopen every writer implementing
+// Reopener, it does not block and is safe to call from a signal handler
+func (l *Logger) Reopen() {
+	select {
+	case l.root().reopen <- true:
+	default:
 	}
 }
 
+// ReopenOnSignal reopen writers whenever one of sigs is received,
+// SIGHUP if none is given, for use with logrotate in create mode
+func (l *Logger) ReopenOnSignal(sigs ...os.Signal) {
+	l = l.root()
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	if l.signals == nil {
+		l.signals = make(chan os.Signal, 1)
+		go func(c chan os.Signal) {
+			for range c {
+				l.Reopen()
+			}
+		}(l.signals)
+	}
+	signal.Notify(l.signals, sigs...)
+}
+
 func (l *Logger) deliverRecordToWriter(level int, format string, args ...interface{}) {
 	var inf, code string
+	fields := l.fields
+	l = l.root()
 
-	/*	if level < l.level {
+	if int32(level) < atomic.LoadInt32(&l.threshold) {
 		return
-	}*/
+	}
 
 	if format != "" {
 		inf = fmt.Sprintf(format, args...)
@@ -142,7 +263,7 @@ func (l *Logger) deliverRecordToWriter(level int, format string, args ...interfa
 	}
 
 	// source code, file and line num
-	_, file, line, ok := runtime.Caller(2)
+	pc, file, line, ok := runtime.Caller(2)
 	if ok {
 		if l.fullPath {
 			code = file + ":" + strconv.Itoa(line)
@@ -163,6 +284,11 @@ func (l *Logger) deliverRecordToWriter(level int, format string, args ...interfa
 	r.code = code
 	r.time = l.lastTimeStr
 	r.level = level
+	r.fields = fields
+	r.now = now
+	r.file = file
+	r.line = line
+	r.pc = pc
 
 	l.tunnel <- r
 }
@@ -226,6 +352,15 @@ func boostrapLogWriter(logger *Logger) {
 				}
 			}
 			rotateTimer.Reset(time.Second * 10)
+
+		case <-logger.reopen:
+			for _, w := range logger.writers {
+				if r, ok := w.(Reopener); ok {
+					if err := r.Reopen(); err != nil {
+						log.Println(err)
+					}
+				}
+			}
 		}
 	}
 }
@@ -236,10 +371,14 @@ var (
 	takeup         = false
 )
 
-// SetLevel global set level is ignore
-// logger level should be set in specific logger
+// SetLevel set the minimum level of the default logger
 func SetLevel(lvl int) {
-	//logger_default.level = lvl
+	logger_default.SetLevel(lvl)
+}
+
+// Enabled report whether the default logger would output a record at level
+func Enabled(level int) bool {
+	return logger_default.Enabled(level)
 }
 
 func SetLayout(layout string) {
@@ -266,6 +405,11 @@ func Fatal(fmt string, args ...interface{}) {
 	logger_default.deliverRecordToWriter(FATAL, fmt, args...)
 }
 
+// With create a child of the default logger carrying the given key/value pairs
+func With(kvs ...interface{}) *Logger {
+	return logger_default.With(kvs...)
+}
+
 func Register(w Writer) {
 	logger_default.Register(w)
 }
@@ -274,6 +418,16 @@ func Close() {
 	logger_default.Close()
 }
 
+// Reopen reopen the writers of the default logger
+func Reopen() {
+	logger_default.Reopen()
+}
+
+// ReopenOnSignal reopen the writers of the default logger on sigs, SIGHUP if none
+func ReopenOnSignal(sigs ...os.Signal) {
+	logger_default.ReopenOnSignal(sigs...)
+}
+
 // ShowFullPath show full path
 func ShowFullPath(show bool) {
 	logger_default.fullPath = show
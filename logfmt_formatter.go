@@ -0,0 +1,75 @@
+package log4go
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+// LogfmtFormatter renders records as logfmt lines, eg:
+//
+//	ts=2018-11-05T10:00:00.000+08:00 level=info caller=main.go:12 msg="user login" user=42
+//
+// An empty key name omits that key.
+type LogfmtFormatter struct {
+	TimeKey    string
+	LevelKey   string
+	CallerKey  string
+	MessageKey string
+	TimeLayout string // default time.RFC3339Nano
+}
+
+// NewLogfmtFormatter create logfmt formatter with keys ts, level, caller and msg
+func NewLogfmtFormatter() *LogfmtFormatter {
+	return &LogfmtFormatter{
+		TimeKey:    "ts",
+		LevelKey:   "level",
+		CallerKey:  "caller",
+		MessageKey: "msg",
+		TimeLayout: time.RFC3339Nano,
+	}
+}
+
+// Format render r as a single logfmt line
+func (f *LogfmtFormatter) Format(r *Record) string {
+	var buf bytes.Buffer
+
+	layout := f.TimeLayout
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+	writeLogfmtPair(&buf, f.TimeKey, r.now.Format(layout))
+	writeLogfmtPair(&buf, f.LevelKey, strings.ToLower(LEVEL_FLAGS[r.level]))
+	writeLogfmtPair(&buf, f.CallerKey, r.code)
+	writeLogfmtPair(&buf, f.MessageKey, r.info)
+	for _, field := range r.fields {
+		writeLogfmtPair(&buf, field.Key, fieldString(field.Value))
+	}
+	buf.WriteByte('\n')
+	return buf.String()
+}
+
+func writeLogfmtPair(buf *bytes.Buffer, key, value string) {
+	if key == "" {
+		return
+	}
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(logfmtKey(key))
+	buf.WriteByte('=')
+	buf.WriteString(quoteFieldValue(value))
+}
+
+// logfmtKey keys cannot be quoted, chars that would break parsing become '_'
+func logfmtKey(key string) string {
+	if !needsQuote(key) {
+		return key
+	}
+	return strings.Map(func(c rune) rune {
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			return '_'
+		}
+		return c
+	}, key)
+}
@@ -0,0 +1,258 @@
+package log4go
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultConversionPattern same output as Record.String
+const DefaultConversionPattern = "%d [%p] <%l> %m%X%n"
+
+/*
+PatternLayout log4j style formatter driven by a conversion pattern.
+
+	%d          time formatted with the logger layout
+	%d{layout}  time formatted with a go layout, eg: %d{2006-01-02 15:04:05.000}
+	%p          level, eg: INFO
+	%c          package of the caller, %c{1} keeps the last path element
+	%F          file name of the caller
+	%L          line number of the caller
+	%l          caller as file:line_number, full path if ShowFullPath is set
+	%M          function name of the caller
+	%m          message
+	%X          all structured fields as " k=v k=v"
+	%X{key}     value of a single structured field
+	%n          newline
+	%%          literal percent sign
+
+A format modifier may follow the percent sign: %-5p pads to 5 chars on
+the right, %5p pads on the left, %.30c keeps at most the last 30 chars.
+*/
+type PatternLayout struct {
+	pattern    string
+	converters []patternConverter
+}
+
+type patternConverter struct {
+	literal   string
+	verb      byte
+	option    string
+	min       int
+	max       int
+	leftAlign bool
+}
+
+// NewPatternLayout parse the conversion pattern
+func NewPatternLayout(pattern string) (*PatternLayout, error) {
+	converters, err := parseConversionPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &PatternLayout{
+		pattern:    pattern,
+		converters: converters,
+	}, nil
+}
+
+// Pattern conversion pattern of the layout
+func (p *PatternLayout) Pattern() string {
+	return p.pattern
+}
+
+// Format render r with the conversion pattern
+func (p *PatternLayout) Format(r *Record) string {
+	var buf bytes.Buffer
+	for i := range p.converters {
+		c := &p.converters[i]
+		if c.verb == 0 {
+			buf.WriteString(c.literal)
+			continue
+		}
+		c.write(&buf, c.convert(r))
+	}
+	return buf.String()
+}
+
+func (c *patternConverter) convert(r *Record) string {
+	switch c.verb {
+	case 'd':
+		if c.option == "" {
+			return r.time
+		}
+		return r.now.Format(c.option)
+	case 'p':
+		return LEVEL_FLAGS[r.level]
+	case 'c':
+		return abbreviatePackage(callerPackage(r.Function()), c.option)
+	case 'F':
+		return path.Base(r.file)
+	case 'L':
+		return strconv.Itoa(r.line)
+	case 'l':
+		return r.code
+	case 'M':
+		return callerFunction(r.Function())
+	case 'm':
+		return r.info
+	case 'X':
+		if c.option == "" {
+			return formatFields(r.fields)
+		}
+		for _, f := range r.fields {
+			if f.Key == c.option {
+				return fieldString(f.Value)
+			}
+		}
+		return ""
+	case 'n':
+		return "\n"
+	}
+	return ""
+}
+
+// write apply min width padding and max width truncation, truncation
+// keeps the rightmost chars as log4j does, widths count runes
+func (c *patternConverter) write(buf *bytes.Buffer, s string) {
+	n := utf8.RuneCountInString(s)
+	if c.max > 0 && n > c.max {
+		runes := []rune(s)
+		s = string(runes[n-c.max:])
+		n = c.max
+	}
+	pad := c.min - n
+	if pad > 0 && !c.leftAlign {
+		buf.WriteString(strings.Repeat(" ", pad))
+	}
+	buf.WriteString(s)
+	if pad > 0 && c.leftAlign {
+		buf.WriteString(strings.Repeat(" ", pad))
+	}
+}
+
+func parseConversionPattern(pattern string) ([]patternConverter, error) {
+	converters := make([]patternConverter, 0, 8)
+	var literal bytes.Buffer
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' {
+			literal.WriteByte(pattern[i])
+			continue
+		}
+		i++
+		if i >= len(pattern) {
+			return nil, errors.New("Invalid conversion pattern (" + pattern + "): trailing %")
+		}
+		if pattern[i] == '%' {
+			literal.WriteByte('%')
+			continue
+		}
+
+		c := patternConverter{}
+		if pattern[i] == '-' {
+			c.leftAlign = true
+			i++
+		}
+		i, c.min = parseDigits(pattern, i)
+		if i < len(pattern) && pattern[i] == '.' {
+			i, c.max = parseDigits(pattern, i+1)
+		}
+		if i >= len(pattern) {
+			return nil, errors.New("Invalid conversion pattern (" + pattern + "): missing conversion character")
+		}
+
+		c.verb = pattern[i]
+		if !strings.ContainsRune("dpcFLlMmXn", rune(c.verb)) {
+			return nil, errors.New("Invalid conversion pattern (" + pattern + "): unknown conversion %" + string(c.verb))
+		}
+		if i+1 < len(pattern) && pattern[i+1] == '{' {
+			end := strings.IndexByte(pattern[i+1:], '}')
+			if end < 0 {
+				return nil, errors.New("Invalid conversion pattern (" + pattern + "): unclosed {")
+			}
+			c.option = pattern[i+2 : i+1+end]
+			i += 1 + end
+		}
+
+		if literal.Len() > 0 {
+			converters = append(converters, patternConverter{literal: literal.String()})
+			literal.Reset()
+		}
+		converters = append(converters, c)
+	}
+
+	if literal.Len() > 0 {
+		converters = append(converters, patternConverter{literal: literal.String()})
+	}
+	return converters, nil
+}
+
+func parseDigits(s string, i int) (int, int) {
+	n := 0
+	for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+		n = n*10 + int(s[i]-'0')
+	}
+	return i, n
+}
+
+// callerPackage package path of a fully qualified function name,
+// eg: github.com/kdpujie/log4go.(*Logger).Info -> github.com/kdpujie/log4go
+func callerPackage(function string) string {
+	pkg, _ := splitFunction(function)
+	return pkg
+}
+
+// callerFunction function name without its package path
+func callerFunction(function string) string {
+	_, fn := splitFunction(function)
+	return fn
+}
+
+// splitFunction split a function name at the dot after the package path.
+// A dot in the last element of the path is part of it, eg: gopkg.in/yaml.v2,
+// the runtime reports it escaped as %2e.
+func splitFunction(function string) (pkg, fn string) {
+	slash := strings.LastIndexByte(function, '/')
+	dot := strings.IndexByte(function[slash+1:], '.')
+	if dot < 0 {
+		return function, function
+	}
+	dot += slash + 1
+	for {
+		next := strings.IndexByte(function[dot+1:], '.')
+		if next < 0 || !isMajorVersion(function[dot+1:dot+1+next]) {
+			break
+		}
+		dot += 1 + next
+	}
+	return strings.Replace(function[:dot], "%2e", ".", -1), function[dot+1:]
+}
+
+// isMajorVersion report whether s is a version suffix such as v2
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// abbreviatePackage keep the last n path elements when option is a number
+func abbreviatePackage(pkg, option string) string {
+	n, err := strconv.Atoi(option)
+	if err != nil || n <= 0 {
+		return pkg
+	}
+	parts := strings.Split(pkg, "/")
+	if len(parts) <= n {
+		return pkg
+	}
+	return strings.Join(parts[len(parts)-n:], "/")
+}
@@ -0,0 +1,103 @@
+package log4go
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestParseConversionPattern(t *testing.T) {
+	tests := []struct {
+		pattern    string
+		converters []patternConverter
+		err        bool
+	}{
+		{"", nil, false},
+		{"plain", []patternConverter{{literal: "plain"}}, false},
+		{"100%%", []patternConverter{{literal: "100%"}}, false},
+		{"%m%n", []patternConverter{{verb: 'm'}, {verb: 'n'}}, false},
+		{"[%-5p] ", []patternConverter{{literal: "["}, {verb: 'p', min: 5, leftAlign: true}, {literal: "] "}}, false},
+		{"%10.20c{2}", []patternConverter{{verb: 'c', option: "2", min: 10, max: 20}}, false},
+		{"%.30m", []patternConverter{{verb: 'm', max: 30}}, false},
+		{"%d{2006-01-02 15:04:05} %X{user}", []patternConverter{
+			{verb: 'd', option: "2006-01-02 15:04:05"}, {literal: " "}, {verb: 'X', option: "user"}}, false},
+		{"%", nil, true},
+		{"%-5", nil, true},
+		{"%q", nil, true},
+		{"%d{2006", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseConversionPattern(tt.pattern)
+		if (err != nil) != tt.err {
+			t.Errorf("parseConversionPattern(%q) error = %v, want error %v", tt.pattern, err, tt.err)
+			continue
+		}
+		if tt.err {
+			continue
+		}
+		if len(got) == 0 && len(tt.converters) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.converters) {
+			t.Errorf("parseConversionPattern(%q) = %+v, want %+v", tt.pattern, got, tt.converters)
+		}
+	}
+}
+
+func TestPatternConverterWrite(t *testing.T) {
+	tests := []struct {
+		converter patternConverter
+		s         string
+		want      string
+	}{
+		{patternConverter{}, "INFO", "INFO"},
+		{patternConverter{min: 6}, "INFO", "  INFO"},
+		{patternConverter{min: 6, leftAlign: true}, "INFO", "INFO  "},
+		{patternConverter{min: 2}, "INFO", "INFO"},
+		{patternConverter{max: 3}, "abcdef", "def"},
+		{patternConverter{max: 10}, "abcdef", "abcdef"},
+		{patternConverter{min: 5, max: 3, leftAlign: true}, "abcdef", "def  "},
+		// widths count runes, truncation never splits one
+		{patternConverter{min: 8, leftAlign: true}, "日志模块", "日志模块    "},
+		{patternConverter{min: 6}, "日志", "    日志"},
+		{patternConverter{max: 3}, "日志模块", "志模块"},
+		{patternConverter{max: 2}, "aé日", "é日"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		c := tt.converter
+		c.write(&buf, tt.s)
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%+v write(%q) = %q, want %q", tt.converter, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestSplitFunction(t *testing.T) {
+	tests := []struct {
+		function string
+		pkg      string
+		fn       string
+	}{
+		{"main.main", "main", "main"},
+		{"github.com/kdpujie/log4go.(*Logger).Info", "github.com/kdpujie/log4go", "(*Logger).Info"},
+		{"github.com/kdpujie/log4go.With.func1", "github.com/kdpujie/log4go", "With.func1"},
+		{"gopkg.in/yaml.v2.Unmarshal", "gopkg.in/yaml.v2", "Unmarshal"},
+		{"gopkg.in/yaml.v2.(*decoder).unmarshal", "gopkg.in/yaml.v2", "(*decoder).unmarshal"},
+		{"gopkg.in/yaml%2ev2.Unmarshal", "gopkg.in/yaml.v2", "Unmarshal"},
+		{"example.com/mod/v2.Func", "example.com/mod/v2", "Func"},
+		{"example.com/x.v10.v2", "example.com/x.v10", "v2"},
+		{"nodot", "nodot", "nodot"},
+	}
+
+	for _, tt := range tests {
+		if got := callerPackage(tt.function); got != tt.pkg {
+			t.Errorf("callerPackage(%q) = %q, want %q", tt.function, got, tt.pkg)
+		}
+		if got := callerFunction(tt.function); got != tt.fn {
+			t.Errorf("callerFunction(%q) = %q, want %q", tt.function, got, tt.fn)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package log4go
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// Record a single log event, use the accessors to read it from a Writer
+// implemented outside this package, see Writer for the lifetime contract
+type Record struct {
+	time   string
+	code   string
+	info   string
+	level  int
+	fields []Field
+
+	now  time.Time
+	file string
+	line int
+	pc   uintptr
+}
+
+func (r *Record) String() string {
+	return fmt.Sprintf("%s [%s] <%s> %s%s\n", r.time, LEVEL_FLAGS[r.level], r.code, r.info, formatFields(r.fields))
+}
+
+// Time time the record was created
+func (r *Record) Time() time.Time {
+	return r.now
+}
+
+// TimeString creation time formatted with the logger layout
+func (r *Record) TimeString() string {
+	return r.time
+}
+
+// Level level of the record, DEBUG to FATAL
+func (r *Record) Level() int {
+	return r.level
+}
+
+// LevelString level name of the record, eg: INFO
+func (r *Record) LevelString() string {
+	return LEVEL_FLAGS[r.level]
+}
+
+// Message formatted log message
+func (r *Record) Message() string {
+	return r.info
+}
+
+// Caller source code as file:line_number, full path if ShowFullPath is set
+func (r *Record) Caller() string {
+	return r.code
+}
+
+// File full path of the source file that emitted the record
+func (r *Record) File() string {
+	return r.file
+}
+
+// Line line number in File
+func (r *Record) Line() int {
+	return r.line
+}
+
+// Function fully qualified name of the calling function, empty if unknown
+func (r *Record) Function() string {
+	if r.pc == 0 {
+		return ""
+	}
+	if fn := runtime.FuncForPC(r.pc); fn != nil {
+		return fn.Name()
+	}
+	return ""
+}
+
+// Fields structured fields of the record, the slice must not be modified
+func (r *Record) Fields() []Field {
+	return r.fields
+}
+
+// Clone copy of the record that stays valid after Write returns
+func (r *Record) Clone() *Record {
+	c := *r
+	if r.fields != nil {
+		c.fields = make([]Field, len(r.fields))
+		copy(c.fields, r.fields)
+	}
+	return &c
+}
@@ -3,24 +3,50 @@ package log4go
 import (
 	"errors"
 	"log/syslog"
+	"strings"
 )
 
 type ShortRecord Record
 
 func (r *ShortRecord) String() string {
-	return "<" + r.code + "> " + r.info
+	return "<" + r.code + "> " + r.info + formatFields(r.fields)
 }
 
 type SyslogWriter struct {
-	level   int
-	network string
-	addr    string
-	tag     string
-	writer  *syslog.Writer
+	level    int
+	network  string
+	addr     string
+	tag      string
+	facility syslog.Priority
+	writer   *syslog.Writer
+
+	formatter Formatter
 }
 
 func NewSyslogWriter() *SyslogWriter {
-	return &SyslogWriter{}
+	return NewSyslogWriterWithLevel(DEBUG)
+}
+
+// NewSyslogWriterWithLevel create new syslog writer with level
+func NewSyslogWriterWithLevel(level int) *SyslogWriter {
+	defaultLevel := DEBUG
+	maxLevel := len(LEVEL_FLAGS)
+	if maxLevel >= 1 {
+		maxLevel = maxLevel - 1
+	}
+
+	if level >= defaultLevel && level <= maxLevel {
+		defaultLevel = level
+	}
+	return &SyslogWriter{
+		level:    defaultLevel,
+		facility: syslog.LOG_SYSLOG,
+	}
+}
+
+// SetLevel minimum level of the syslog writer, call it before Register
+func (w *SyslogWriter) SetLevel(level int) {
+	w.level = level
 }
 
 func (w *SyslogWriter) SetNetwork(network string) {
@@ -35,8 +61,19 @@ func (w *SyslogWriter) SetTag(tag string) {
 	w.tag = tag
 }
 
+// SetFacility syslog facility of the messages, default syslog.LOG_SYSLOG
+func (w *SyslogWriter) SetFacility(facility syslog.Priority) {
+	w.facility = facility
+}
+
+// SetFormatter set the formatter of the syslog writer, the default is
+// "<file:line> message", time and level are added by syslog itself
+func (w *SyslogWriter) SetFormatter(f Formatter) {
+	w.formatter = f
+}
+
 func (w *SyslogWriter) Init() (err error) {
-	w.writer, err = syslog.Dial(w.network, w.addr, syslog.LOG_SYSLOG, w.tag)
+	w.writer, err = syslog.Dial(w.network, w.addr, w.facility, w.tag)
 	return
 }
 
@@ -44,7 +81,12 @@ func (w *SyslogWriter) Write(r *Record) (err error) {
 	if r.level < w.level {
 		return
 	}
-	s := ((*ShortRecord)(r)).String()
+	var s string
+	if w.formatter != nil {
+		s = w.formatter.Format(r)
+	} else {
+		s = ((*ShortRecord)(r)).String()
+	}
 
 	switch r.level {
 	case DEBUG:
@@ -67,3 +109,40 @@ func (w *SyslogWriter) Write(r *Record) (err error) {
 	}
 	return
 }
+
+// Level minimum level of the syslog writer
+func (w *SyslogWriter) Level() int {
+	return w.level
+}
+
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"lpr":      syslog.LOG_LPR,
+	"news":     syslog.LOG_NEWS,
+	"uucp":     syslog.LOG_UUCP,
+	"cron":     syslog.LOG_CRON,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"ftp":      syslog.LOG_FTP,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+// parseFacility facility by name as used in the config file, eg: "local0"
+func parseFacility(name string) (syslog.Priority, error) {
+	facility, ok := syslogFacilities[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, errors.New("Unknown syslog facility (" + name + ")")
+	}
+	return facility, nil
+}